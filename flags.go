@@ -5,9 +5,15 @@ import (
 	"encoding/csv"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/wjam/flac-check/internal/music/cover"
+	"github.com/wjam/flac-check/internal/music/language"
+
+	"github.com/go-flac/flacpicture/v2"
 	"github.com/spf13/pflag"
 )
 
@@ -91,3 +97,176 @@ func (s *stringToIntSliceFlag) Set(val string) error {
 func (s *stringToIntSliceFlag) Type() string {
 	return "stringToIntSlice"
 }
+
+var _ pflag.Value = &pictureTypesFlag{}
+
+type pictureTypesFlag struct {
+	value *[]flacpicture.PictureType
+}
+
+func (p *pictureTypesFlag) String() string {
+	names := make([]string, 0, len(*p.value))
+	for _, t := range *p.value {
+		names = append(names, cover.TypeName(t))
+	}
+	return "[" + strings.Join(names, ",") + "]"
+}
+
+func (p *pictureTypesFlag) Set(val string) error {
+	types, err := cover.ParseTypes(strings.Split(val, ","))
+	if err != nil {
+		return err
+	}
+	*p.value = append(*p.value, types...)
+	return nil
+}
+
+func (p *pictureTypesFlag) Type() string {
+	return "pictureTypes"
+}
+
+var _ pflag.Value = &namesFlag{}
+
+// newNamesValue is a list of names, each of which has to be one of the valid names.
+func newNamesValue(kind string, valid, val []string, p *[]string) *namesFlag {
+	*p = val
+	return &namesFlag{kind: kind, valid: valid, value: p}
+}
+
+type namesFlag struct {
+	kind    string
+	valid   []string
+	value   *[]string
+	changed bool
+}
+
+func (n *namesFlag) String() string {
+	return "[" + strings.Join(*n.value, ",") + "]"
+}
+
+func (n *namesFlag) Set(val string) error {
+	names := strings.Split(val, ",")
+	for _, name := range names {
+		if !slices.Contains(n.valid, name) {
+			return fmt.Errorf("unknown %s %q", n.kind, name)
+		}
+	}
+
+	if !n.changed {
+		*n.value = nil
+		n.changed = true
+	}
+	*n.value = append(*n.value, names...)
+
+	return nil
+}
+
+func (n *namesFlag) Type() string {
+	return "strings"
+}
+
+var _ pflag.Value = &hashAlgorithmFlag{}
+
+func newHashAlgorithmValue(val cover.Algorithm, p *cover.Algorithm) *hashAlgorithmFlag {
+	*p = val
+	return &hashAlgorithmFlag{value: p}
+}
+
+type hashAlgorithmFlag struct {
+	value *cover.Algorithm
+}
+
+func (h *hashAlgorithmFlag) String() string {
+	return string(*h.value)
+}
+
+func (h *hashAlgorithmFlag) Set(val string) error {
+	a, err := cover.ParseAlgorithm(val)
+	if err != nil {
+		return err
+	}
+	*h.value = a
+	return nil
+}
+
+func (h *hashAlgorithmFlag) Type() string {
+	return fmt.Sprintf("%s|%s", cover.DifferenceHashAlgorithm, cover.PerceptualHashAlgorithm)
+}
+
+var _ pflag.Value = &languagesMapFlag{}
+
+// newLanguagesMapValue is the languages allowed for each name, such as an artist, given as name=lang1,lang2.
+func newLanguagesMapValue(val map[string][]string, p *map[string][]string) *languagesMapFlag {
+	*p = val
+	return &languagesMapFlag{value: p}
+}
+
+type languagesMapFlag struct {
+	value   *map[string][]string
+	changed bool
+}
+
+func (l *languagesMapFlag) String() string {
+	records := make([]string, 0, len(*l.value))
+	for _, k := range slices.Sorted(maps.Keys(*l.value)) {
+		records = append(records, k+"="+strings.Join((*l.value)[k], ","))
+	}
+	return "[" + strings.Join(records, ";") + "]"
+}
+
+func (l *languagesMapFlag) Set(val string) error {
+	const keyValuePairLength = 2
+	parts := strings.SplitN(val, "=", keyValuePairLength)
+	if len(parts) != keyValuePairLength {
+		return fmt.Errorf("invalid value %q", val)
+	}
+
+	languages := strings.Split(parts[1], ",")
+	for _, lang := range languages {
+		if !slices.Contains(language.Codes(), lang) {
+			return fmt.Errorf("unknown language %q", lang)
+		}
+	}
+
+	if !l.changed {
+		*l.value = make(map[string][]string)
+		l.changed = true
+	}
+
+	(*l.value)[parts[0]] = append((*l.value)[parts[0]], languages...)
+
+	return nil
+}
+
+func (l *languagesMapFlag) Type() string {
+	return "stringToLanguages"
+}
+
+var _ pflag.Value = &choiceFlag{}
+
+// newChoiceValue is a single value, which has to be one of the valid values.
+func newChoiceValue(valid []string, val string, p *string) *choiceFlag {
+	*p = val
+	return &choiceFlag{valid: valid, value: p}
+}
+
+type choiceFlag struct {
+	valid []string
+	value *string
+}
+
+func (c *choiceFlag) String() string {
+	return *c.value
+}
+
+func (c *choiceFlag) Set(val string) error {
+	if !slices.Contains(c.valid, val) {
+		return fmt.Errorf("expected one of %s, got %q", strings.Join(c.valid, ", "), val)
+	}
+	*c.value = val
+	return nil
+}
+
+func (c *choiceFlag) Type() string {
+	return strings.Join(c.valid, "|")
+}
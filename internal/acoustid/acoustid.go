@@ -0,0 +1,94 @@
+// Package acoustid handles communication to AcoustID, which identifies recordings from their acoustic fingerprint.
+// https://acoustid.org/webservice
+package acoustid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/wjam/flac-check/internal/cache"
+
+	"github.com/carlmjohnson/requests"
+)
+
+type Client struct {
+	key     string
+	configs []requests.Config
+}
+
+const BaseURL = "https://api.acoustid.org/v2/"
+
+// New creates a client using the API key of an AcoustID application.
+func New(key string, opts ...requests.Config) *Client {
+	return &Client{
+		key: key,
+		configs: append([]requests.Config{
+			func(rb *requests.Builder) {
+				rb.BaseURL(BaseURL)
+			},
+			cache.TransportCache(),
+		}, opts...),
+	}
+}
+
+// Lookup finds the AcoustID results for the fingerprint of the start of a track, along with their MusicBrainz
+// recordings & the releases of those, best match first. The duration is the length of the whole track.
+func (c Client) Lookup(ctx context.Context, fingerprint string, duration time.Duration) ([]Result, error) {
+	var res lookupResponse
+	var errRes lookupResponse
+	// The fingerprint stays in the URL the shared transport caches responses by, but the API key is sent in the body
+	// so it isn't logged & doesn't change which responses are cached
+	if err := requests.New(c.configs...).
+		Pathf("./lookup").
+		BodyForm(url.Values{"client": {c.key}}).
+		Param("duration", strconv.Itoa(int(duration.Round(time.Second).Seconds()))).
+		Param("fingerprint", fingerprint).
+		Param("meta", "recordings releaseids").
+		ToJSON(&res).
+		ErrorJSON(&errRes).
+		Fetch(ctx); err != nil {
+		if errRes.Error.Message != "" {
+			return nil, fmt.Errorf("%w: %s", ErrLookupFailed, errRes.Error.Message)
+		}
+		return nil, err
+	}
+
+	if res.Status != "ok" {
+		return nil, fmt.Errorf("%w: %s", ErrLookupFailed, res.Error.Message)
+	}
+
+	return res.Results, nil
+}
+
+// ErrLookupFailed is returned when AcoustID rejects the lookup, such as for an invalid API key.
+var ErrLookupFailed = errors.New("acoustid lookup failed")
+
+type lookupResponse struct {
+	Status  string   `json:"status"`
+	Results []Result `json:"results"`
+	Error   struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type Result struct {
+	ID string `json:"id"`
+	// Score is how closely the fingerprint matched, from 0 to 1
+	Score      float64     `json:"score"`
+	Recordings []Recording `json:"recordings"`
+}
+
+type Recording struct {
+	// ID is the MusicBrainz recording ID
+	ID       string    `json:"id"`
+	Releases []Release `json:"releases"`
+}
+
+type Release struct {
+	// ID is the MusicBrainz release ID
+	ID string `json:"id"`
+}
@@ -3,6 +3,8 @@ package coverart
 import (
 	"bytes"
 	"context"
+	"net/http"
+	"slices"
 
 	"github.com/wjam/flac-check/internal/cache"
 
@@ -26,13 +28,87 @@ func New(opts ...requests.Config) *Client {
 	}
 }
 
+// GetCoverArtFromMusicBrainzReleaseID returns the URL of the large thumbnail of the front cover, if there is one.
 func (c Client) GetCoverArtFromMusicBrainzReleaseID(ctx context.Context, releaseID string) (string, error) {
+	img, err := c.frontImage(ctx, releaseID)
+	if err != nil || img == nil {
+		return "", err
+	}
+
+	return img.largeURL(), nil
+}
+
+// FetchReleaseGroupFrontCover fetches the large thumbnail of the front cover chosen for the release group, from one of
+// its releases, along with the URL it was fetched from. There's no image if the release group doesn't have a front
+// cover.
+func (c Client) FetchReleaseGroupFrontCover(ctx context.Context, releaseGroupID string) ([]byte, string, error) {
+	// The release group endpoint is a sibling of the release endpoint, and redirects to the image. The 500px
+	// thumbnail is the same size as the large thumbnail of the release, rather than the original which can be many MB
+	rb := requests.New(c.configs...).Pathf("../release-group/%s/front-500", releaseGroupID)
+
+	u, err := rb.URL()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var img bytes.Buffer
+	if err := rb.ToBytesBuffer(&img).Fetch(ctx); err != nil {
+		if requests.HasStatusErr(err, http.StatusNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+
+	return img.Bytes(), u.String(), nil
+}
+
+// GetOriginalCoverArtFromMusicBrainzReleaseID returns the URL of the front cover as originally uploaded, if there is
+// one.
+func (c Client) GetOriginalCoverArtFromMusicBrainzReleaseID(ctx context.Context, releaseID string) (string, error) {
+	img, err := c.frontImage(ctx, releaseID)
+	if err != nil || img == nil {
+		return "", err
+	}
+
+	return img.Image, nil
+}
+
+// GetCoverArtOfTypeFromMusicBrainzReleaseID returns the URL of the large thumbnail of the first image of the given
+// type, such as "Back" or "Booklet", if there is one.
+func (c Client) GetCoverArtOfTypeFromMusicBrainzReleaseID(
+	ctx context.Context, releaseID, imageType string,
+) (string, error) {
+	images, err := c.images(ctx, releaseID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, img := range images.Images {
+		if !img.Approved || !slices.Contains(img.Types, imageType) {
+			continue
+		}
+
+		return img.largeURL(), nil
+	}
+
+	return "", nil
+}
+
+func (c Client) images(ctx context.Context, releaseID string) (coverArts, error) {
 	var images coverArts
 	if err := requests.New(c.configs...).
 		Pathf("./%s", releaseID).
 		ToJSON(&images).
 		Fetch(ctx); err != nil {
-		return "", err
+		return coverArts{}, err
+	}
+	return images, nil
+}
+
+func (c Client) frontImage(ctx context.Context, releaseID string) (*coverArt, error) {
+	images, err := c.images(ctx, releaseID)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, img := range images.Images {
@@ -40,15 +116,10 @@ func (c Client) GetCoverArtFromMusicBrainzReleaseID(ctx context.Context, release
 			continue
 		}
 
-		url := img.Image
-		if v, ok := img.Thumbnails["large"]; ok {
-			url = v
-		}
-
-		return url, nil
+		return &img, nil
 	}
 
-	return "", nil
+	return nil, nil //nolint:nilnil // no front cover isn't an error
 }
 
 func (c Client) FetchImage(ctx context.Context, url string) ([]byte, error) {
@@ -62,11 +133,21 @@ func (c Client) FetchImage(ctx context.Context, url string) ([]byte, error) {
 }
 
 type coverArts struct {
-	Images []struct {
-		Approved   bool              `json:"approved"`
-		Back       bool              `json:"back"`
-		Front      bool              `json:"front"`
-		Image      string            `json:"image"`
-		Thumbnails map[string]string `json:"thumbnails"`
-	} `json:"images"`
+	Images []coverArt `json:"images"`
+}
+
+type coverArt struct {
+	Approved   bool              `json:"approved"`
+	Back       bool              `json:"back"`
+	Front      bool              `json:"front"`
+	Image      string            `json:"image"`
+	Types      []string          `json:"types"`
+	Thumbnails map[string]string `json:"thumbnails"`
+}
+
+func (a coverArt) largeURL() string {
+	if v, ok := a.Thumbnails["large"]; ok {
+		return v
+	}
+	return a.Image
 }
@@ -0,0 +1,135 @@
+// Package genius handles communication to Genius, which only has lyrics on the song web pages rather than in the API.
+// https://docs.genius.com/
+package genius
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/cache"
+
+	"github.com/carlmjohnson/requests"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type Client struct {
+	token   string
+	configs []requests.Config
+}
+
+const BaseURL = "https://api.genius.com/"
+
+// New creates a client using the access token of a Genius API client.
+func New(token string, opts ...requests.Config) *Client {
+	return &Client{
+		token: token,
+		configs: append([]requests.Config{
+			func(rb *requests.Builder) {
+				rb.BaseURL(BaseURL)
+			},
+			cache.TransportCache(),
+		}, opts...),
+	}
+}
+
+// FindLyricsForTrack returns the plain lyrics of the song with the same title & artist.
+func (c Client) FindLyricsForTrack(ctx context.Context, track, artist string) (string, error) {
+	var search searchResponse
+	if err := requests.New(c.configs...).
+		Pathf("./search").
+		Param("q", track+" "+artist).
+		Bearer(c.token).
+		ToJSON(&search).
+		Fetch(ctx); err != nil {
+		return "", err
+	}
+
+	for _, hit := range search.Response.Hits {
+		if hit.Type != "song" {
+			continue
+		}
+		if !strings.EqualFold(hit.Result.Title, track) || !strings.EqualFold(hit.Result.PrimaryArtist.Name, artist) {
+			continue
+		}
+
+		return c.lyricsFromPage(ctx, hit.Result.URL)
+	}
+
+	return "", ErrNoLyricsFound
+}
+
+// lyricsFromPage extracts the lyrics from the web page of a song.
+func (c Client) lyricsFromPage(ctx context.Context, url string) (string, error) {
+	var page bytes.Buffer
+	if err := requests.New(c.configs...).
+		BaseURL(url).
+		ToBytesBuffer(&page).
+		Fetch(ctx); err != nil {
+		return "", err
+	}
+
+	doc, err := html.Parse(&page)
+	if err != nil {
+		return "", err
+	}
+
+	var lyrics strings.Builder
+	for n := range doc.Descendants() {
+		if attr(n, "data-lyrics-container") == "true" {
+			writeLyrics(&lyrics, n)
+			lyrics.WriteString("\n")
+		}
+	}
+
+	text := strings.TrimSpace(lyrics.String())
+	if text == "" {
+		return "", ErrNoLyricsFound
+	}
+	return text, nil
+}
+
+// writeLyrics writes the text of a lyrics container, with line breaks as new lines and leaving out the headers that
+// aren't part of the lyrics.
+func writeLyrics(w *strings.Builder, n *html.Node) {
+	for child := range n.ChildNodes() {
+		switch {
+		case child.Type == html.TextNode:
+			w.WriteString(child.Data)
+		case child.DataAtom == atom.Br:
+			w.WriteString("\n")
+		case attr(child, "data-exclude-from-selection") == "true":
+			continue
+		default:
+			writeLyrics(w, child)
+		}
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+type searchResponse struct {
+	Response struct {
+		Hits []struct {
+			Type   string `json:"type"`
+			Result struct {
+				Title         string `json:"title"`
+				URL           string `json:"url"`
+				PrimaryArtist struct {
+					Name string `json:"name"`
+				} `json:"primary_artist"`
+			} `json:"result"`
+		} `json:"hits"`
+	} `json:"response"`
+}
+
+var ErrNoLyricsFound = errors.New("no lyrics found")
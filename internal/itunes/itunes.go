@@ -0,0 +1,66 @@
+// Package itunes handles communication to the iTunes Search API.
+// https://performance-partners.apple.com/search-api
+package itunes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/cache"
+
+	"github.com/carlmjohnson/requests"
+)
+
+type Client struct {
+	configs []requests.Config
+}
+
+const BaseURL = "https://itunes.apple.com/search"
+
+func New(opts ...requests.Config) *Client {
+	return &Client{
+		configs: append([]requests.Config{
+			func(rb *requests.Builder) {
+				rb.BaseURL(BaseURL)
+			},
+			cache.TransportCache(),
+		}, opts...),
+	}
+}
+
+// GetCoverArtForAlbum returns the URL of the 600x600 artwork of the album with exactly the given artist & title, if
+// there is one.
+func (c Client) GetCoverArtForAlbum(ctx context.Context, artist, album string) (string, error) {
+	var search searchResults
+	if err := requests.New(c.configs...).
+		Param("term", artist+" "+album).
+		Param("media", "music").
+		Param("entity", "album").
+		ToJSON(&search).
+		Fetch(ctx); err != nil {
+		return "", err
+	}
+
+	for _, result := range search.Results {
+		if !strings.EqualFold(result.ArtistName, artist) || !strings.EqualFold(result.CollectionName, album) {
+			continue
+		}
+		if result.ArtworkURL100 == "" {
+			continue
+		}
+
+		// The artwork is available in other sizes by changing the size in the URL
+		return strings.Replace(result.ArtworkURL100, "/100x100bb.", "/600x600bb.", 1), nil
+	}
+
+	return "", nil
+}
+
+type searchResults struct {
+	ResultCount int `json:"resultCount"`
+	Results     []struct {
+		ArtistName     string `json:"artistName"`
+		CollectionName string `json:"collectionName"`
+		ArtworkURL100  string `json:"artworkUrl100"`
+	} `json:"results"`
+}
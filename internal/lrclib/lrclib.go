@@ -1,9 +1,15 @@
 package lrclib
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/wjam/flac-check/internal/cache"
 
@@ -27,15 +33,22 @@ func New(opts ...requests.Config) *Client {
 	}
 }
 
-func (c Client) FindLyricsForTrack(ctx context.Context, track, artist, album string) (*Lyrics, error) {
+// FindLyricsForTrack gets the lyrics of the track with exactly the same title, artist & album. LRCLIB uses the
+// duration, if known, to pick between versions of the track.
+func (c Client) FindLyricsForTrack(
+	ctx context.Context, track, artist, album string, duration time.Duration,
+) (*Lyrics, error) {
 	var lyrics Lyrics
-	if err := requests.New(c.configs...).
+	req := requests.New(c.configs...).
 		Pathf("./get").
 		Param("artist_name", artist).
 		Param("album_name", album).
 		Param("track_name", track).
-		ToJSON(&lyrics).
-		Fetch(ctx); err != nil {
+		ToJSON(&lyrics)
+	if duration > 0 {
+		req.Param("duration", strconv.Itoa(int(duration.Round(time.Second).Seconds())))
+	}
+	if err := req.Fetch(ctx); err != nil {
 		if requests.HasStatusErr(err, http.StatusNotFound) {
 			return nil, ErrNoLyricsFound
 		}
@@ -45,10 +58,137 @@ func (c Client) FindLyricsForTrack(ctx context.Context, track, artist, album str
 	return &lyrics, nil
 }
 
+// MinSimilarity is how similar, from 0 to 1, the normalised title & artist of a search result have to be to those of
+// the track.
+const MinSimilarity = 0.9
+
+// SearchLyricsForTrack searches for lyrics of the track by any album, for when the album name doesn't exactly match.
+// Results are ranked by how similar their title & artist are and how close their duration is, preferring synced
+// lyrics. If the duration of the track is known, results have to be within the tolerance of it.
+func (c Client) SearchLyricsForTrack(
+	ctx context.Context, track, artist string, duration, tolerance time.Duration,
+) (*Lyrics, error) {
+	var results []Lyrics
+	if err := requests.New(c.configs...).
+		Pathf("./search").
+		Param("artist_name", artist).
+		Param("track_name", track).
+		ToJSON(&results).
+		Fetch(ctx); err != nil {
+		if requests.HasStatusErr(err, http.StatusNotFound) {
+			return nil, ErrNoLyricsFound
+		}
+		return nil, err
+	}
+
+	type candidate struct {
+		lyrics     Lyrics
+		similarity float64
+		difference time.Duration
+	}
+
+	var candidates []candidate
+	for _, result := range results {
+		titleSimilarity := similarity(normalise(result.TrackName), normalise(track))
+		artistSimilarity := similarity(normalise(result.ArtistName), normalise(artist))
+		if titleSimilarity < MinSimilarity || artistSimilarity < MinSimilarity {
+			continue
+		}
+
+		var difference time.Duration
+		if duration > 0 {
+			difference = (result.duration() - duration).Abs()
+			if difference > tolerance {
+				continue
+			}
+		}
+
+		candidates = append(candidates, candidate{
+			lyrics:     result,
+			similarity: titleSimilarity + artistSimilarity,
+			difference: difference,
+		})
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrNoLyricsFound
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(b.similarity, a.similarity),
+			cmp.Compare(a.difference, b.difference),
+		)
+	})
+
+	for _, c := range candidates {
+		if c.lyrics.SyncedLyrics != "" {
+			return &c.lyrics, nil
+		}
+	}
+
+	return &candidates[0].lyrics, nil
+}
+
+// similarity is how similar two strings are, from 0 for nothing in common to 1 for the same, based on the number of
+// edits needed to turn one into the other.
+func similarity(a, b string) float64 {
+	x, y := []rune(a), []rune(b)
+	longest := max(len(x), len(y))
+	if longest == 0 {
+		return 1
+	}
+
+	// Levenshtein distance, keeping only the previous row
+	previous := make([]int, len(y)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := range x {
+		current := make([]int, len(y)+1)
+		current[0] = i + 1
+		for j := range y {
+			substitution := previous[j]
+			if x[i] != y[j] {
+				substitution++
+			}
+			current[j+1] = min(previous[j+1]+1, current[j]+1, substitution)
+		}
+		previous = current
+	}
+
+	return 1 - float64(previous[len(y)])/float64(longest)
+}
+
+// normalise reduces a name to lowercase letters & digits, dropping anything in brackets such as "(Remastered)".
+func normalise(name string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			depth = max(0, depth-1)
+		case depth == 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
 type Lyrics struct {
-	Instrumental bool   `json:"instrumental"`
-	PlainLyrics  string `json:"plainLyrics"`
-	SyncedLyrics string `json:"syncedLyrics"`
+	TrackName    string  `json:"trackName"`
+	ArtistName   string  `json:"artistName"`
+	AlbumName    string  `json:"albumName"`
+	Duration     float64 `json:"duration"`
+	Instrumental bool    `json:"instrumental"`
+	PlainLyrics  string  `json:"plainLyrics"`
+	SyncedLyrics string  `json:"syncedLyrics"`
+}
+
+func (l Lyrics) duration() time.Duration {
+	return time.Duration(l.Duration * float64(time.Second))
 }
 
 var ErrNoLyricsFound = errors.New("no lyrics found")
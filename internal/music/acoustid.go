@@ -0,0 +1,175 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/wjam/flac-check/internal/acoustid"
+	"github.com/wjam/flac-check/internal/logging"
+	"github.com/wjam/flac-check/internal/music/chromaprint"
+	"github.com/wjam/flac-check/internal/music/track"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+)
+
+// MinAcoustIDScore is how closely, from 0 to 1, the fingerprint of a track has to match an AcoustID result for the
+// result to be used.
+const MinAcoustIDScore = 0.8
+
+// addAcoustIDs fingerprints the tracks of the album missing their AcoustID or MusicBrainz recording & release, looking
+// them up on AcoustID to fill those in. The release is only filled in when it's the only one every track found is on.
+// Tracks whose MusicBrainz recording isn't one AcoustID has for their audio are probably mis-tagged, so are warned
+// about.
+func (s *Scan) addAcoustIDs(ctx context.Context, a album) error {
+	found := map[*track.Track][]acoustid.Recording{}
+	for _, t := range a {
+		if hasAcoustIDTags(t) {
+			continue
+		}
+
+		recordings, ok, err := s.lookupAcoustID(ctx, t)
+		if err != nil {
+			return fmt.Errorf("failed to look up track %s on AcoustID: %w", t, err)
+		}
+		if ok {
+			found[t] = recordings
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+
+	var release string
+	if releases := a.getTag(vorbis.MusicBrainzAlbumIDTag); len(releases) == 1 {
+		release = releases[0]
+	} else if len(releases) == 0 {
+		release = commonRelease(ctx, slices.Collect(maps.Values(found)))
+	}
+
+	for _, t := range a {
+		recordings, ok := found[t]
+		if !ok {
+			continue
+		}
+
+		if _, ok := t.TagOk(vorbis.MusicBrainzAlbumIDTag); !ok && release != "" {
+			t.SetMusicBrainzAlbumID(release)
+		}
+
+		if ids, ok := t.TagOk(vorbis.MusicBrainzTrackIDTag); ok {
+			if !slices.ContainsFunc(recordings, func(r acoustid.Recording) bool { return slices.Contains(ids, r.ID) }) {
+				logging.FromContext(ctx).WarnContext(
+					ctx, "MusicBrainz recording of track doesn't match its audio on AcoustID",
+					slog.String("track", t.String()), slog.Any("recording", ids),
+				)
+			}
+			continue
+		}
+
+		if recording, ok := pickRecording(recordings, release); ok {
+			t.SetMusicBrainzTrackID(recording)
+		}
+	}
+
+	return nil
+}
+
+func hasAcoustIDTags(t *track.Track) bool {
+	for _, tag := range []vorbis.Tag{
+		vorbis.AcoustIDTag,
+		vorbis.AcoustIDFingerprintTag,
+		vorbis.MusicBrainzTrackIDTag,
+		vorbis.MusicBrainzAlbumIDTag,
+	} {
+		if _, ok := t.TagOk(tag); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupAcoustID fingerprints the track and looks it up on AcoustID, setting the AcoustID of the best result and
+// returning the MusicBrainz recordings of it. Tracks too short to fingerprint, or without a good enough result, are
+// skipped.
+func (s *Scan) lookupAcoustID(ctx context.Context, t *track.Track) ([]acoustid.Recording, bool, error) {
+	duration, ok := t.Duration()
+	if !ok {
+		return nil, false, nil
+	}
+
+	fingerprint, err := t.Fingerprint()
+	if err != nil {
+		if errors.Is(err, chromaprint.ErrTooShort) {
+			logging.FromContext(ctx).DebugContext(ctx, "Track is too short to fingerprint", slog.String("track", t.String()))
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	results, err := s.acoust.Lookup(ctx, fingerprint, duration)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var best *acoustid.Result
+	for _, r := range results {
+		if r.Score >= MinAcoustIDScore && (best == nil || r.Score > best.Score) {
+			best = &r
+		}
+	}
+	if best == nil {
+		logging.FromContext(ctx).InfoContext(ctx, "Unable to find track on AcoustID", slog.String("track", t.String()))
+		return nil, false, nil
+	}
+
+	t.SetAcoustID(best.ID, fingerprint)
+	return best.Recordings, true, nil
+}
+
+// commonRelease returns the only release every track has a recording on, or nothing if there isn't exactly one.
+func commonRelease(ctx context.Context, tracks [][]acoustid.Recording) string {
+	var common []string
+	for i, recordings := range tracks {
+		var releases []string
+		for _, r := range recordings {
+			for _, rel := range r.Releases {
+				if i == 0 || slices.Contains(common, rel.ID) {
+					releases = append(releases, rel.ID)
+				}
+			}
+		}
+		common = releases
+	}
+
+	slices.Sort(common)
+	common = slices.Compact(common)
+	if len(common) != 1 {
+		logging.FromContext(ctx).InfoContext(
+			ctx, "Unable to find a single release on AcoustID for the album", slog.Any("releases", common),
+		)
+		return ""
+	}
+	return common[0]
+}
+
+// pickRecording returns the recording on the release, or the only recording if the release isn't known.
+func pickRecording(recordings []acoustid.Recording, release string) (string, bool) {
+	if len(recordings) == 1 && release == "" {
+		return recordings[0].ID, true
+	}
+
+	var matching []string
+	for _, r := range recordings {
+		if slices.ContainsFunc(r.Releases, func(rel acoustid.Release) bool { return rel.ID == release }) {
+			matching = append(matching, r.ID)
+		}
+	}
+	if len(matching) != 1 {
+		return "", false
+	}
+	return matching[0], true
+}
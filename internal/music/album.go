@@ -1,6 +1,8 @@
 package music
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"maps"
 	"math"
@@ -27,7 +29,7 @@ func (a album) getTag(name vorbis.Tag) []string {
 }
 
 // Tags that should be consistent across tracks in an album.
-func (a album) validateTags(silenceTracks map[string][]int) []error {
+func (a album) validateTags(silent silentTracks) []error {
 	var errs []error
 
 	//nolint:exhaustive // shorter code rather than covering all scenarios
@@ -69,12 +71,30 @@ func (a album) validateTags(silenceTracks map[string][]int) []error {
 		errs = append(errs, err)
 	}
 
+	errs = append(errs, a.validateReplayGain()...)
+	if err := a.validateFrontCovers(); err != nil {
+		errs = append(errs, err)
+	}
 	errs = append(errs, a.validateDiscNumbers()...)
-	errs = append(errs, a.validateTrackNumbers(silenceTracks)...)
+	errs = append(errs, a.validateTrackNumbers(silent)...)
 
 	return errs
 }
 
+// validate checks the tags of each track within the album, along with the album as a whole.
+func (a album) validate(silent silentTracks) error {
+	var errs []error
+	for _, t := range a {
+		if err := t.ValidateTags(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to handle track %s: %w", t, err))
+		}
+	}
+
+	errs = append(errs, a.validateTags(silent)...)
+
+	return errors.Join(errs...)
+}
+
 func (a album) validateDiscNumbers() []error {
 	discNumbers := map[int]struct{}{}
 	lowest := math.MaxInt32
@@ -119,9 +139,11 @@ func (a album) validateDiscNumbers() []error {
 	return errs
 }
 
-func (a album) validateTrackNumbers(silenceTracks map[string][]int) []error {
-	discTracks := a.readDiscTrackNumberCounts()
+// silentTracks are the track numbers, by disc, which may be missing from an album as they're only silence or data.
+type silentTracks map[int][]int
 
+// configuredSilentTracks looks up the silent tracks configured by "artist/album", which apply to every disc.
+func (a album) configuredSilentTracks(silenceTracks map[string][]int) silentTracks {
 	var albumName string
 	if v, ok := a[0].TagOk(vorbis.AlbumTag); ok {
 		albumName = v[0]
@@ -133,14 +155,20 @@ func (a album) validateTrackNumbers(silenceTracks map[string][]int) []error {
 		artist = v[0]
 	}
 
-	silenceTracksForAlbum := silenceTracks[fmt.Sprintf("%s/%s", artist, albumName)]
+	numbers := silenceTracks[fmt.Sprintf("%s/%s", artist, albumName)]
 
+	silent := silentTracks{}
+	for disc := range a.readDiscTrackNumberCounts() {
+		silent[disc] = slices.Clone(numbers)
+	}
+	return silent
+}
+
+func (a album) validateTrackNumbers(silent silentTracks) []error {
 	// TODO validate TRACKTOTAL is same across tracks for disc and matches highest given silenceTracks
 
 	var errs []error
-	for disk, tracks := range discTracks {
-		lowest := 1
-		highest := math.MinInt32
+	for disk, tracks := range a.readDiscTrackNumberCounts() {
 		for trackNumber, count := range tracks {
 			if count > 1 {
 				errs = append(errs, DiscTrackNumberCollisionError{
@@ -149,22 +177,40 @@ func (a album) validateTrackNumbers(silenceTracks map[string][]int) []error {
 					Count:       count,
 				})
 			}
+		}
+	}
+
+	for disk, missing := range a.missingTrackNumbers(silent) {
+		for _, i := range missing {
+			errs = append(errs, MissingTrackNumberError{
+				TrackNumber: i,
+				Disc:        disk,
+			})
+		}
+	}
+
+	return errs
+}
+
+// missingTrackNumbers returns the track numbers, by disc, missing from the album which aren't known to be silent.
+func (a album) missingTrackNumbers(silent silentTracks) map[int][]int {
+	missing := map[int][]int{}
+	for disk, tracks := range a.readDiscTrackNumberCounts() {
+		lowest := 1
+		highest := math.MinInt32
+		for trackNumber := range tracks {
 			if trackNumber > highest {
 				highest = trackNumber
 			}
 		}
 
 		for i := lowest; i <= highest; i++ {
-			if _, ok := tracks[i]; !ok && !slices.Contains(silenceTracksForAlbum, i) {
-				errs = append(errs, MissingTrackNumberError{
-					TrackNumber: i,
-					Disc:        disk,
-				})
+			if _, ok := tracks[i]; !ok && !slices.Contains(silent[disk], i) {
+				missing[disk] = append(missing[disk], i)
 			}
 		}
 	}
-
-	return errs
+	return missing
 }
 
 func (a album) readDiscTrackNumberCounts() map[int]map[int]int {
@@ -196,6 +242,43 @@ func (a album) readDiscTrackNumberCounts() map[int]map[int]int {
 	return discTracks
 }
 
+// discs groups the tracks of the album by disc number, with each disc ordered by track number.
+func (a album) discs() map[int][]*track.Track {
+	discs := map[int][]*track.Track{}
+	for _, t := range a {
+		disc, ok := discNumber(t)
+		if !ok {
+			continue
+		}
+		if _, ok := trackNumber(t); !ok {
+			continue
+		}
+		discs[disc] = append(discs[disc], t)
+	}
+
+	for _, tracks := range discs {
+		slices.SortFunc(tracks, func(a, b *track.Track) int {
+			i, _ := trackNumber(a)
+			j, _ := trackNumber(b)
+			return i - j
+		})
+	}
+
+	return discs
+}
+
+func discNumber(t *track.Track) (int, bool) {
+	return t.IntTag(vorbis.DiscNumberTag)
+}
+
+func trackNumber(t *track.Track) (int, bool) {
+	return t.IntTag(vorbis.TrackNumberTag)
+}
+
+func trackTotal(t *track.Track) (int, bool) {
+	return t.IntTag(vorbis.TrackTotalTag)
+}
+
 func (a album) validateConsistentGenre() error {
 	genres, _ := a[0].TagOk(vorbis.GenreTag)
 
@@ -235,3 +318,60 @@ func (a album) validateMusicBrainzTags() error {
 
 	return nil
 }
+
+// validateReplayGain checks that, if any track has ReplayGain tags, every track does with the same album gain & peak.
+func (a album) validateReplayGain() []error {
+	var missing []string
+	present := false
+	for _, t := range a {
+		if _, ok := t.TagOk(vorbis.ReplayGainAlbumGainTag); ok {
+			present = true
+		} else {
+			missing = append(missing, t.String())
+		}
+	}
+	if !present {
+		return nil
+	}
+
+	var errs []error
+	if len(missing) > 0 {
+		errs = append(errs, MissingReplayGainError{Tracks: missing})
+	}
+
+	for _, tag := range []vorbis.Tag{vorbis.ReplayGainAlbumGainTag, vorbis.ReplayGainAlbumPeakTag} {
+		if values := a.getTag(tag); len(values) > 1 {
+			errs = append(errs, InvalidValueError{
+				Tag:         tag,
+				Values:      values,
+				Expectation: "single",
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateFrontCovers checks every track with a front cover has the same front cover.
+func (a album) validateFrontCovers() error {
+	var first []byte
+	var different []string
+	for _, t := range a {
+		data, ok := t.PictureData()
+		if !ok {
+			continue
+		}
+		if first == nil {
+			first = data
+			continue
+		}
+		if !bytes.Equal(first, data) {
+			different = append(different, t.String())
+		}
+	}
+
+	if len(different) > 0 {
+		return InconsistentFrontCoverError{Tracks: different}
+	}
+	return nil
+}
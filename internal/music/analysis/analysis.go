@@ -0,0 +1,103 @@
+// Package analysis measures problems with the mastering of audio - clipping & DC offset - a channel at a time.
+package analysis
+
+// MinClipRun is the number of consecutive samples at full scale which count as clipping, as a single full scale sample
+// can be a legitimate peak.
+const MinClipRun = 3
+
+// Channel is the analysis of a single channel.
+type Channel struct {
+	Samples int64
+	// ClippedSamples is the number of samples within clipped runs
+	ClippedSamples int64
+	// ClippedRuns is the number of runs of at least MinClipRun consecutive samples at full scale
+	ClippedRuns int
+	// DCOffset is the mean of the samples, as a ratio of full scale
+	DCOffset float64
+}
+
+// Clipping is the share of the samples which are clipped.
+func (c Channel) Clipping() float64 {
+	if c.Samples == 0 {
+		return 0
+	}
+	return float64(c.ClippedSamples) / float64(c.Samples)
+}
+
+// Meter analyses audio a frame at a time.
+type Meter struct {
+	maxSample, minSample int64
+	scale                float64
+	channels             []*channel
+}
+
+type channel struct {
+	samples int64
+	sum     int64
+	// run is the number of consecutive samples at full scale so far, carried over between frames
+	run     int
+	clipped int64
+	runs    int
+}
+
+func NewMeter(bitDepth, channels int) *Meter {
+	m := &Meter{
+		maxSample: int64(1)<<(bitDepth-1) - 1,
+		minSample: -(int64(1) << (bitDepth - 1)),
+		scale:     float64(uint64(1) << (bitDepth - 1)),
+	}
+	for range channels {
+		m.channels = append(m.channels, &channel{})
+	}
+	return m
+}
+
+// Write adds samples to the analysis - one slice of samples per channel.
+func (m *Meter) Write(samples [][]int32) {
+	for c, ch := range m.channels {
+		for _, s := range samples[c] {
+			ch.samples++
+			ch.sum += int64(s)
+
+			if int64(s) == m.maxSample || int64(s) == m.minSample {
+				ch.run++
+				continue
+			}
+			ch.endRun()
+		}
+	}
+}
+
+func (c *channel) endRun() {
+	if c.run >= MinClipRun {
+		c.clipped += int64(c.run)
+		c.runs++
+	}
+	c.run = 0
+}
+
+// Channels returns the analysis of each channel of all the audio written so far.
+func (m *Meter) Channels() []Channel {
+	channels := make([]Channel, 0, len(m.channels))
+	for _, ch := range m.channels {
+		// A track ending while clipping still counts, without ending the run in case more audio is written
+		clipped, runs := ch.clipped, ch.runs
+		if ch.run >= MinClipRun {
+			clipped += int64(ch.run)
+			runs++
+		}
+
+		var offset float64
+		if ch.samples > 0 {
+			offset = float64(ch.sum) / float64(ch.samples) / m.scale
+		}
+
+		channels = append(channels, Channel{
+			Samples:        ch.samples,
+			ClippedSamples: clipped,
+			ClippedRuns:    runs,
+			DCOffset:       offset,
+		})
+	}
+	return channels
+}
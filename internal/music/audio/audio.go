@@ -0,0 +1,102 @@
+// Package audio decodes and manipulates the audio frames of a FLAC stream.
+// https://www.rfc-editor.org/rfc/rfc9639.html#name-frame-structure
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"iter"
+
+	"github.com/go-flac/go-flac/v2"
+	"github.com/mewkiz/flac/frame"
+)
+
+const (
+	streamInfoLength = 34
+	md5Length        = 16
+)
+
+// Frame is a decoded audio frame along with the encoded bytes it was decoded from.
+type Frame struct {
+	*frame.Frame
+	// Offset is the byte offset of the frame from the start of the first frame.
+	Offset int
+	// Sample is the number of the first sample in the frame.
+	Sample uint64
+	Raw    []byte
+}
+
+// Frames decodes each frame in turn from the encoded audio data following the metadata blocks.
+func Frames(data []byte) iter.Seq2[Frame, error] {
+	return func(yield func(Frame, error) bool) {
+		r := bytes.NewReader(data)
+		var sample uint64
+		for r.Len() > 0 {
+			offset := len(data) - r.Len()
+			f, err := frame.Parse(r)
+			if err != nil {
+				yield(Frame{}, err)
+				return
+			}
+
+			end := len(data) - r.Len()
+			if !yield(Frame{Frame: f, Offset: offset, Sample: sample, Raw: data[offset:end]}, nil) {
+				return
+			}
+			sample += uint64(f.BlockSize)
+		}
+	}
+}
+
+// Float returns the samples of each channel of the frame as a ratio of full scale.
+func (f Frame) Float(bitDepth int) [][]float64 {
+	scale := float64(uint64(1) << (bitDepth - 1))
+	channels := make([][]float64, 0, len(f.Subframes))
+	for _, sub := range f.Subframes {
+		samples := make([]float64, len(sub.Samples))
+		for i, s := range sub.Samples {
+			samples[i] = float64(s) / scale
+		}
+		channels = append(channels, samples)
+	}
+	return channels
+}
+
+// MarshalStreamInfo encodes a STREAMINFO metadata block.
+func MarshalStreamInfo(info *flac.StreamInfoBlock) flac.MetaDataBlock {
+	data := make([]byte, 0, streamInfoLength)
+	data = binary.BigEndian.AppendUint16(data, uint16(info.BlockSizeMin)) //nolint:gosec // 16 bit field
+	data = binary.BigEndian.AppendUint16(data, uint16(info.BlockSizeMax)) //nolint:gosec // 16 bit field
+	data = appendUint24(data, uint32(info.FrameSizeMin))                  //nolint:gosec // 24 bit field
+	data = appendUint24(data, uint32(info.FrameSizeMax))                  //nolint:gosec // 24 bit field
+	packed := uint64(info.SampleRate)<<44 |                               //nolint:gosec // 20 bit field
+		uint64(info.ChannelCount-1)<<41 | //nolint:gosec // 3 bit field
+		uint64(info.BitDepth-1)<<36 | //nolint:gosec // 5 bit field
+		uint64(info.SampleCount) //nolint:gosec // 36 bit field
+	data = binary.BigEndian.AppendUint64(data, packed)
+	md5 := make([]byte, md5Length)
+	copy(md5, info.AudioMD5)
+	data = append(data, md5...)
+
+	return flac.MetaDataBlock{
+		Type: flac.StreamInfo,
+		Data: data,
+	}
+}
+
+// AudioOffset returns the offset of the first audio frame in a FLAC file.
+func AudioOffset(f *flac.File) int {
+	const (
+		markerLength = 4
+		headerLength = 4
+	)
+	offset := markerLength
+	for _, m := range f.Meta {
+		offset += headerLength + len(m.Data)
+	}
+	return offset
+}
+
+func appendUint24(b []byte, v uint32) []byte {
+	return append(b, byte(v>>16), byte(v>>8), byte(v)) //nolint:mnd // big-endian 24 bit value
+}
@@ -0,0 +1,173 @@
+package audio
+
+import (
+	"crypto/md5" //nolint:gosec // FLAC defines the audio signature as MD5
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/go-flac/go-flac/v2"
+)
+
+const (
+	syncByte          = 0xFF
+	variableBlockSize = 0xF9
+	// blockSize16Bit means the block size minus one follows the coded number as a 16 bit value.
+	blockSize16Bit  = 0x7
+	verbatimSubtype = 0x02
+	bitsPerByte     = 8
+)
+
+// Cut writes the audio between the start (inclusive) and end (exclusive) samples as a new stream of frames. Frames
+// entirely within the range are copied as-is, with only the frame header renumbered, and only the frames that
+// straddle either end are split. The STREAMINFO for the new stream is returned.
+func Cut(w io.Writer, data []byte, info *flac.StreamInfoBlock, start, end uint64) (*flac.StreamInfoBlock, error) {
+	c := &cutter{
+		w:        w,
+		md5:      md5.New(), //nolint:gosec // FLAC defines the audio signature as MD5
+		bitDepth: info.BitDepth,
+	}
+
+	for f, err := range Frames(data) {
+		if err != nil {
+			return nil, err
+		}
+
+		frameEnd := f.Sample + uint64(f.BlockSize)
+		if frameEnd <= start {
+			continue
+		}
+		if f.Sample >= end {
+			break
+		}
+
+		if f.Sample >= start && frameEnd <= end {
+			err = c.copyFrame(f)
+		} else {
+			err = c.splitFrame(f, max(start, f.Sample)-f.Sample, min(end, frameEnd)-f.Sample)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.samples != end-start {
+		return nil, errors.New("audio ended before the end of the cut")
+	}
+
+	return c.streamInfo(info), nil
+}
+
+type cutter struct {
+	w        io.Writer
+	md5      hash.Hash
+	bitDepth int
+	samples  uint64
+
+	blockSizeMin, blockSizeMax int
+	lastBlockSize              int
+	frameSizeMin, frameSizeMax int
+}
+
+// copyFrame writes the frame unchanged apart from the header, which is renumbered as part of a variable block size
+// stream - as the frames that have been split stop the stream from having a fixed block size.
+func (c *cutter) copyFrame(f Frame) error {
+	raw := f.Raw
+	headerLength, numberLength := headerLayout(raw)
+
+	b := make([]byte, 0, len(raw)+1)
+	b = append(b, raw[0], raw[1]|1, raw[2], raw[3])
+	b = appendCodedNumber(b, c.samples)
+	b = append(b, raw[4+numberLength:headerLength-1]...)
+	b = append(b, crc8(b))
+	b = append(b, raw[headerLength:len(raw)-2]...)
+	b = binary.BigEndian.AppendUint16(b, crc16(b))
+
+	c.hashSamples(f, 0, uint64(f.BlockSize))
+
+	return c.write(b, int(f.BlockSize))
+}
+
+// splitFrame writes part of a frame as a new frame, storing the samples verbatim.
+func (c *cutter) splitFrame(f Frame, from, to uint64) error {
+	blockSize := to - from
+	channels := len(f.Subframes)
+
+	b := []byte{
+		syncByte,
+		variableBlockSize,
+		blockSize16Bit<<4 | sampleRateCode(int(f.SampleRate)),
+		byte(channels-1)<<4 | bitDepthCode(c.bitDepth)<<1,
+	}
+	b = appendCodedNumber(b, c.samples)
+	b = binary.BigEndian.AppendUint16(b, uint16(blockSize-1)) //nolint:gosec // block sizes are 16 bit
+	b = append(b, crc8(b))
+
+	var bw bitWriter
+	for _, sub := range f.Subframes {
+		bw.write(verbatimSubtype, bitsPerByte)
+		for _, s := range sub.Samples[from:to] {
+			bw.write(uint64(s), c.bitDepth) //nolint:gosec // two's complement truncated to bit depth
+		}
+	}
+	b = append(b, bw.bytes()...)
+	b = binary.BigEndian.AppendUint16(b, crc16(b))
+
+	c.hashSamples(f, from, to)
+
+	return c.write(b, int(blockSize)) //nolint:gosec // block sizes are 16 bit
+}
+
+func (c *cutter) write(b []byte, blockSize int) error {
+	if _, err := c.w.Write(b); err != nil {
+		return err
+	}
+
+	// The minimum block size excludes the last frame, so only account for the previous frame now there is another
+	if c.lastBlockSize != 0 && (c.blockSizeMin == 0 || c.lastBlockSize < c.blockSizeMin) {
+		c.blockSizeMin = c.lastBlockSize
+	}
+	c.lastBlockSize = blockSize
+	c.blockSizeMax = max(c.blockSizeMax, blockSize)
+	if c.frameSizeMin == 0 || len(b) < c.frameSizeMin {
+		c.frameSizeMin = len(b)
+	}
+	c.frameSizeMax = max(c.frameSizeMax, len(b))
+	c.samples += uint64(blockSize) //nolint:gosec // block sizes are 16 bit
+
+	return nil
+}
+
+// hashSamples adds the samples to the MD5 signature - interleaved, little-endian & signed.
+func (c *cutter) hashSamples(f Frame, from, to uint64) {
+	width := (c.bitDepth + bitsPerByte - 1) / bitsPerByte
+	buf := make([]byte, 0, int(to-from)*len(f.Subframes)*width) //nolint:gosec // block sizes are 16 bit
+	for i := from; i < to; i++ {
+		for _, sub := range f.Subframes {
+			s := sub.Samples[i]
+			for b := range width {
+				buf = append(buf, byte(s>>(b*bitsPerByte)))
+			}
+		}
+	}
+	_, _ = c.md5.Write(buf)
+}
+
+func (c *cutter) streamInfo(original *flac.StreamInfoBlock) *flac.StreamInfoBlock {
+	blockSizeMin := c.blockSizeMin
+	if blockSizeMin == 0 {
+		blockSizeMin = c.lastBlockSize
+	}
+	return &flac.StreamInfoBlock{
+		BlockSizeMin: blockSizeMin,
+		BlockSizeMax: c.blockSizeMax,
+		FrameSizeMin: c.frameSizeMin,
+		FrameSizeMax: c.frameSizeMax,
+		SampleRate:   original.SampleRate,
+		ChannelCount: original.ChannelCount,
+		BitDepth:     original.BitDepth,
+		SampleCount:  int64(c.samples), //nolint:gosec // 36 bit field
+		AudioMD5:     c.md5.Sum(nil),
+	}
+}
@@ -0,0 +1,145 @@
+package audio
+
+import "math/bits"
+
+// headerLayout returns the length of the frame header, including the CRC-8, and the length of the coded number.
+func headerLayout(raw []byte) (int, int) {
+	const fixedHeaderLength = 4
+	numberLength := bits.LeadingZeros8(^raw[fixedHeaderLength])
+	if numberLength == 0 {
+		numberLength = 1
+	}
+
+	length := fixedHeaderLength + numberLength
+	switch raw[2] >> 4 {
+	case 0x6:
+		length++
+	case blockSize16Bit:
+		length += 2
+	}
+	switch raw[2] & 0x0F {
+	case 0xC:
+		length++
+	case 0xD, 0xE:
+		length += 2
+	}
+
+	return length + 1, numberLength
+}
+
+// appendCodedNumber appends the frame or sample number using the UTF-8 like encoding of FLAC frame headers.
+func appendCodedNumber(b []byte, n uint64) []byte {
+	if n < 0x80 {
+		return append(b, byte(n))
+	}
+
+	// Number of continuation bytes, each holding 6 bits, needed alongside the bits which fit in the first byte
+	continuation := 1
+	for n >= 1<<(6*continuation+(6-continuation)) && continuation < 6 {
+		continuation++
+	}
+
+	first := byte(0xFF << (7 - continuation))
+	b = append(b, first|byte(n>>(6*continuation)))
+	for i := continuation - 1; i >= 0; i-- {
+		b = append(b, 0x80|byte(n>>(6*i))&0x3F)
+	}
+	return b
+}
+
+func sampleRateCode(rate int) byte {
+	codes := map[int]byte{
+		88200:  0x1,
+		176400: 0x2,
+		192000: 0x3,
+		8000:   0x4,
+		16000:  0x5,
+		22050:  0x6,
+		24000:  0x7,
+		32000:  0x8,
+		44100:  0x9,
+		48000:  0xA,
+		96000:  0xB,
+	}
+	// Anything else is taken from STREAMINFO
+	return codes[rate]
+}
+
+func bitDepthCode(depth int) byte {
+	codes := map[int]byte{
+		8:  0x1,
+		12: 0x2,
+		16: 0x4,
+		20: 0x5,
+		24: 0x6,
+		32: 0x7,
+	}
+	// Anything else is taken from STREAMINFO
+	return codes[depth]
+}
+
+// crc8 is the CRC-8 of a frame header, with polynomial x^8 + x^2 + x^1 + x^0.
+func crc8(data []byte) byte {
+	var crc byte
+	for _, d := range data {
+		crc ^= d
+		for range 8 {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// crc16 is the CRC-16 of a whole frame, with polynomial x^16 + x^15 + x^2 + x^0.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, d := range data {
+		crc = crc16Update(crc, d)
+	}
+	return crc
+}
+
+// crc16Update adds the next byte to the CRC-16, for calculating it a byte at a time.
+func crc16Update(crc uint16, d byte) uint16 {
+	crc ^= uint16(d) << 8
+	for range 8 {
+		if crc&0x8000 != 0 {
+			crc = crc<<1 ^ 0x8005
+		} else {
+			crc <<= 1
+		}
+	}
+	return crc
+}
+
+// bitWriter packs values into bytes, most significant bit first.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	count int
+}
+
+func (w *bitWriter) write(v uint64, n int) {
+	for n > 0 {
+		take := min(n, 56-w.count)
+		w.acc = w.acc<<take | (v>>(n-take))&(1<<take-1)
+		w.count += take
+		n -= take
+		for w.count >= 8 {
+			w.buf = append(w.buf, byte(w.acc>>(w.count-8)))
+			w.count -= 8
+		}
+	}
+}
+
+// bytes returns the packed values, zero padded to a whole byte.
+func (w *bitWriter) bytes() []byte {
+	if w.count > 0 {
+		w.write(0, 8-w.count)
+	}
+	return w.buf
+}
@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/mewkiz/flac/frame"
+)
+
+// crc16Size is the length of the CRC-16 at the end of every frame.
+const crc16Size = 2
+
+// Header is the header of an audio frame, along with where the frame is.
+type Header struct {
+	frame.Header
+	// Offset is the byte offset of the frame from the start of the first frame.
+	Offset int
+	// Sample is the number of the first sample in the frame.
+	Sample uint64
+	// Length is the number of bytes in the frame.
+	Length int
+}
+
+// ErrFrameEnd is returned when the end of a frame can't be found.
+var ErrFrameEnd = errors.New("unable to find the end of the audio frame")
+
+// Headers reads the header of each frame in turn from the encoded audio data following the metadata blocks, without
+// decoding the audio. Frames don't record their length, so the end of each frame is found by the next sync code which
+// follows a matching CRC-16 & starts a valid header.
+func Headers(data []byte) iter.Seq2[Header, error] {
+	return func(yield func(Header, error) bool) {
+		var sample uint64
+		for offset := 0; offset < len(data); {
+			h, err := parseHeader(data[offset:])
+			if err != nil {
+				yield(Header{}, fmt.Errorf("invalid audio frame at offset %d: %w", offset, err))
+				return
+			}
+
+			length, ok := frameLength(data[offset:])
+			if !ok {
+				yield(Header{}, fmt.Errorf("%w at offset %d", ErrFrameEnd, offset))
+				return
+			}
+
+			if !yield(Header{Header: h, Offset: offset, Sample: sample, Length: length}, nil) {
+				return
+			}
+			sample += uint64(h.BlockSize)
+			offset += length
+		}
+	}
+}
+
+func parseHeader(data []byte) (frame.Header, error) {
+	f, err := frame.New(bytes.NewReader(data))
+	if err != nil {
+		return frame.Header{}, err
+	}
+	return f.Header, nil
+}
+
+// frameLength finds the length of the frame at the start of the data, being the first point at which the CRC-16 of
+// the frame, including the CRC-16 itself, is zero and either the data ends or another frame starts.
+func frameLength(data []byte) (int, bool) {
+	minLength, _ := headerLayout(data)
+	minLength += crc16Size
+
+	var crc uint16
+	for i, b := range data {
+		crc = crc16Update(crc, b)
+		end := i + 1
+		if crc != 0 || end < minLength {
+			continue
+		}
+		if end == len(data) || isFrameStart(data[end:]) {
+			return end, true
+		}
+	}
+	return 0, false
+}
+
+// isFrameStart is whether the data starts with a sync code & a header with a valid CRC-8.
+func isFrameStart(data []byte) bool {
+	const syncCode = 0xFFF8
+	if len(data) < crc16Size || binary.BigEndian.Uint16(data)&^1 != syncCode {
+		return false
+	}
+	_, err := parseHeader(data)
+	return err == nil
+}
@@ -0,0 +1,118 @@
+package music
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-flac/go-flac/v2"
+)
+
+// DefaultMaxClipping is the percentage of samples which can be clipped by default - a few short runs in a loud master.
+const DefaultMaxClipping = 0.01
+
+// DefaultMaxDCOffset is the DC offset, as a percentage of full scale, a channel can have by default.
+const DefaultMaxDCOffset = 0.5
+
+// DefaultMinTrackDuration is the length below which a track is abnormally short, such as a download which was cut
+// short.
+const DefaultMinTrackDuration = time.Second
+
+type audioProperty struct {
+	name  string
+	value func(*flac.StreamInfoBlock) int
+}
+
+// audioProperties returns the properties from the STREAMINFO block which should be the same for every track of an
+// album.
+func audioProperties() []audioProperty {
+	return []audioProperty{
+		{"sample rate", func(i *flac.StreamInfoBlock) int { return i.SampleRate }},
+		{"bit depth", func(i *flac.StreamInfoBlock) int { return i.BitDepth }},
+		{"channel count", func(i *flac.StreamInfoBlock) int { return i.ChannelCount }},
+	}
+}
+
+// validateAudioProperties checks every track of the album has the same sample rate, bit depth & channel count, so a
+// track re-downloaded at a different resolution stands out, and that no track has an unknown or abnormally short
+// length. Tracks without a STREAMINFO block are skipped.
+func (a album) validateAudioProperties(minDuration time.Duration) []error {
+	var errs []error
+	for _, p := range audioProperties() {
+		tracks := map[int][]string{}
+		for _, t := range a {
+			if info := t.StreamInfo(); info != nil {
+				tracks[p.value(info)] = append(tracks[p.value(info)], t.String())
+			}
+		}
+		if len(tracks) > 1 {
+			errs = append(errs, MixedAudioPropertyError{Property: p.name, Tracks: tracks})
+		}
+	}
+
+	for _, t := range a {
+		info := t.StreamInfo()
+		if info == nil {
+			continue
+		}
+		if info.SampleCount == 0 {
+			errs = append(errs, UnknownTotalSamplesError{Track: t.String()})
+			continue
+		}
+		if duration, ok := t.Duration(); ok && duration < minDuration {
+			errs = append(errs, ShortTrackError{Track: t.String(), Duration: duration, Min: minDuration})
+		}
+	}
+
+	return errs
+}
+
+// validateAudioAnalysis checks the clipping & DC offset of every track which has had its audio analysed, with the
+// limits given as percentages.
+func (a album) validateAudioAnalysis(maxClipping, maxDCOffset float64) []error {
+	var errs []error
+	for _, t := range a {
+		analysis := t.AudioAnalysis()
+		if analysis == nil {
+			continue
+		}
+
+		var samples, clipped int64
+		for _, c := range analysis.Channels {
+			samples += c.Samples
+			clipped += c.ClippedSamples
+		}
+		if samples > 0 && percent(float64(clipped)/float64(samples)) > maxClipping {
+			errs = append(errs, ClippingError{
+				Track:    t.String(),
+				Clipping: roundPercent(float64(clipped) / float64(samples)),
+				Max:      maxClipping,
+			})
+		}
+
+		for i, c := range analysis.Channels {
+			if percent(math.Abs(c.DCOffset)) > maxDCOffset {
+				errs = append(errs, DCOffsetError{
+					Track:    t.String(),
+					Channel:  i + 1,
+					DCOffset: roundPercent(c.DCOffset),
+					Max:      maxDCOffset,
+				})
+			}
+		}
+	}
+	return errs
+}
+
+func percent(ratio float64) float64 {
+	return ratio * 100 //nolint:mnd // ratio to percentage
+}
+
+// roundPercent converts the ratio to a percentage, to the precision it's reported at.
+func roundPercent(ratio float64) float64 {
+	rounded := math.Round(percent(ratio)*1000) / 1000 //nolint:mnd // thousandths of a percent
+	if rounded == 0 {
+		// Rather than -0 for tiny negative ratios
+		return 0
+	}
+	return rounded
+}
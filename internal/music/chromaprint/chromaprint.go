@@ -0,0 +1,232 @@
+// Package chromaprint calculates the acoustic fingerprint of audio the same way as Chromaprint, the library behind
+// AcoustID, so the fingerprint can be looked up on AcoustID.
+//
+// The audio is mixed to mono & resampled to 11025Hz, then the energy of each note of the chromatic scale is measured
+// in overlapping blocks. The changes in those notes over time are classified into 32 bit sub-fingerprints. Only the
+// resampling differs from Chromaprint, which only flips the odd bit of the fingerprint - AcoustID matches fingerprints
+// by how many bits differ, so lookups aren't affected.
+// https://oxygene.sk/2011/01/how-does-chromaprint-work/
+package chromaprint
+
+import (
+	"encoding/base64"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/wjam/flac-check/internal/music/fft"
+)
+
+const (
+	// SampleRate is the sample rate the audio is resampled to before being fingerprinted.
+	SampleRate = 11025
+	// MaxDuration is how much of the start of the audio is fingerprinted, matching the default of fpcalc.
+	MaxDuration = 120 * time.Second
+
+	// algorithm is the Chromaprint algorithm the fingerprint is calculated with, which is the default of Chromaprint.
+	algorithm = 1
+	// frameSize is the number of samples in each block the notes are measured over.
+	frameSize = 4096
+	// frameStep is the number of samples between the start of each block, so blocks overlap by two thirds.
+	frameStep = frameSize / 3
+	// minFreq & maxFreq are the range of frequencies, in Hz, the notes are measured over.
+	minFreq = 28
+	maxFreq = 3520
+	// notes is the number of notes in the chromatic scale.
+	notes = 12
+	// minNorm is the length of the vector of notes below which the block is treated as silence.
+	minNorm = 0.01
+)
+
+// filterCoefficients returns the coefficients which smooth the notes over consecutive blocks.
+func filterCoefficients() []float64 {
+	return []float64{0.25, 0.75, 1.0, 0.75, 0.25}
+}
+
+// ErrTooShort is returned when there isn't enough audio to calculate a fingerprint from.
+var ErrTooShort = errors.New("audio is too short to fingerprint")
+
+// Fingerprinter calculates a fingerprint a frame of audio at a time.
+type Fingerprinter struct {
+	// scale converts samples to the 16 bit range Chromaprint works with
+	scale float64
+	// remaining is the number of samples at the original sample rate still to be fingerprinted
+	remaining int64
+	resample  *resampler
+	// block is the resampled audio, until there are enough samples for a block
+	block []float64
+	fft   *fft.FFT
+	// noteOf is the note of each frequency bin within the range measured
+	noteOf   []int
+	minIndex int
+	// recent are the notes of the most recent blocks, for the filter
+	recent [][notes]float64
+	image  [][notes]float64
+}
+
+func NewFingerprinter(sampleRate, bitDepth int) *Fingerprinter {
+	f := &Fingerprinter{
+		scale:     math.Pow(2, float64(16-bitDepth)), //nolint:mnd // 16 bit audio
+		remaining: int64(MaxDuration.Seconds()) * int64(sampleRate),
+		resample:  newResampler(sampleRate, SampleRate),
+		block:     make([]float64, 0, frameSize),
+		// Chromaprint scales the window so 16 bit samples are within -1 to 1
+		fft:      fft.New(fft.Hamming(frameSize, 1.0/math.MaxInt16)),
+		minIndex: max(1, freqToIndex(minFreq)),
+	}
+
+	maxIndex := min(frameSize/2, freqToIndex(maxFreq)) //nolint:mnd // up to the Nyquist frequency
+	for i := f.minIndex; i < maxIndex; i++ {
+		freq := float64(i) * SampleRate / frameSize
+		// Octaves start at A0, a quarter of the way into the octave above 27.5Hz
+		octave := math.Log2(freq / (440.0 / 16)) //nolint:mnd // A4 is 440Hz, four octaves above A0
+		f.noteOf = append(f.noteOf, int(notes*(octave-math.Floor(octave))))
+	}
+	return f
+}
+
+func freqToIndex(freq float64) int {
+	return int(math.Round(frameSize * freq / SampleRate))
+}
+
+// Write adds samples to the fingerprint - one slice of samples per channel. Samples after MaxDuration are ignored.
+func (f *Fingerprinter) Write(samples [][]int32) {
+	n := min(int64(len(samples[0])), f.remaining)
+	f.remaining -= n
+
+	for i := range n {
+		var mono float64
+		for _, channel := range samples {
+			mono += float64(channel[i])
+		}
+		f.resample.write(mono*f.scale/float64(len(samples)), f.writeResampled)
+	}
+}
+
+// Done is whether MaxDuration of audio has been written, so there's no need to decode any more.
+func (f *Fingerprinter) Done() bool {
+	return f.remaining == 0
+}
+
+func (f *Fingerprinter) writeResampled(sample float64) {
+	f.block = append(f.block, sample)
+	if len(f.block) < frameSize {
+		return
+	}
+
+	f.addBlock()
+	f.block = append(f.block[:0], f.block[frameStep:]...)
+}
+
+// addBlock measures the notes of a block, adding them to the image once there are enough blocks to filter.
+func (f *Fingerprinter) addBlock() {
+	var chroma [notes]float64
+	for i, power := range f.fft.Power(f.block)[f.minIndex : f.minIndex+len(f.noteOf)] {
+		chroma[f.noteOf[i]] += power
+	}
+
+	coefficients := filterCoefficients()
+	f.recent = append(f.recent, chroma)
+	if len(f.recent) < len(coefficients) {
+		return
+	}
+	f.recent = f.recent[len(f.recent)-len(coefficients):]
+
+	var filtered [notes]float64
+	for i, c := range coefficients {
+		for note := range filtered {
+			filtered[note] += f.recent[i][note] * c
+		}
+	}
+
+	var norm float64
+	for _, v := range filtered {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	for note := range filtered {
+		if norm < minNorm {
+			filtered[note] = 0
+		} else {
+			filtered[note] /= norm
+		}
+	}
+
+	f.image = append(f.image, filtered)
+}
+
+// Fingerprint returns the sub-fingerprints of all the audio written so far.
+func (f *Fingerprinter) Fingerprint() ([]uint32, error) {
+	img := newIntegralImage(f.image)
+	if img.rows() < maxFilterWidth {
+		return nil, ErrTooShort
+	}
+
+	classifiers := classifiers()
+	fingerprint := make([]uint32, 0, img.rows()-maxFilterWidth+1)
+	for offset := 0; offset+maxFilterWidth <= img.rows(); offset++ {
+		var bits uint32
+		for _, c := range classifiers {
+			bits = bits<<2 | grayCode(c.classify(img, offset))
+		}
+		fingerprint = append(fingerprint, bits)
+	}
+	return fingerprint, nil
+}
+
+// Encode compresses the sub-fingerprints into the URL safe base64 form used by fpcalc and the AcoustID API.
+func Encode(fingerprint []uint32) string {
+	var normal, exceptional []int
+	var previous uint32
+	for _, sub := range fingerprint {
+		// Only the bits which changed since the previous sub-fingerprint are stored, as the gaps between them
+		x := sub ^ previous
+		previous = sub
+		last := 0
+		for bit := 1; x != 0; bit, x = bit+1, x>>1 {
+			if x&1 == 0 {
+				continue
+			}
+			if gap := bit - last; gap >= maxNormalGap {
+				normal = append(normal, maxNormalGap)
+				exceptional = append(exceptional, gap-maxNormalGap)
+			} else {
+				normal = append(normal, gap)
+			}
+			last = bit
+		}
+		normal = append(normal, 0)
+	}
+
+	out := []byte{
+		algorithm,
+		byte(len(fingerprint) >> 16), //nolint:mnd // 24 bit big endian length
+		byte(len(fingerprint) >> 8),  //nolint:mnd // 24 bit big endian length
+		byte(len(fingerprint)),
+	}
+	out = pack(out, normal, 3)      //nolint:mnd // gaps below maxNormalGap fit in 3 bits
+	out = pack(out, exceptional, 5) //nolint:mnd // the rest of larger gaps fit in 5 bits
+	return base64.RawURLEncoding.EncodeToString(out)
+}
+
+// maxNormalGap is the gap between changed bits which has the rest of the gap stored separately.
+const maxNormalGap = 7
+
+// pack appends the values, each of the given number of bits, least significant bit first.
+func pack(out []byte, values []int, width int) []byte {
+	var acc uint32
+	var bits int
+	for _, v := range values {
+		acc |= uint32(v) << bits //nolint:gosec // values fit within the width
+		bits += width
+		for bits >= 8 {
+			out = append(out, byte(acc))
+			acc >>= 8
+			bits -= 8
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc))
+	}
+	return out
+}
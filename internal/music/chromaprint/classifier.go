@@ -0,0 +1,127 @@
+package chromaprint
+
+import (
+	"math"
+)
+
+// maxFilterWidth is the widest filter, in blocks, so the number of blocks needed for each sub-fingerprint.
+const maxFilterWidth = 16
+
+// grayCode converts the quantized value of a classifier into the bits of the sub-fingerprint, so neighbouring values
+// only differ by a single bit.
+func grayCode(value int) uint32 {
+	return uint32(value ^ value>>1) //nolint:gosec // quantized values are never negative
+}
+
+// classifiers returns the filters & quantizer thresholds Chromaprint calculates each pair of bits of a sub-fingerprint
+// with, learnt by Chromaprint from a set of tracks.
+func classifiers() []classifier {
+	return []classifier{
+		{filter{0, 4, 3, 15}, quantizer{1.98215, 2.35817, 2.63523}},
+		{filter{4, 4, 6, 15}, quantizer{-1.03809, -0.651211, -0.282167}},
+		{filter{1, 0, 4, 16}, quantizer{-0.298702, 0.119262, 0.558497}},
+		{filter{3, 8, 2, 12}, quantizer{-0.105439, 0.0153946, 0.135898}},
+		{filter{3, 4, 4, 8}, quantizer{-0.142891, 0.0258736, 0.200632}},
+		{filter{4, 0, 3, 5}, quantizer{-0.826319, -0.590612, -0.368214}},
+		{filter{1, 2, 2, 9}, quantizer{-0.557409, -0.233035, 0.0534525}},
+		{filter{2, 7, 3, 4}, quantizer{-0.0646826, 0.00620476, 0.0784847}},
+		{filter{2, 6, 2, 16}, quantizer{-0.192387, -0.029699, 0.215855}},
+		{filter{2, 1, 3, 2}, quantizer{-0.0397818, -0.00568076, 0.0292026}},
+		{filter{5, 10, 1, 15}, quantizer{-0.53823, -0.369934, -0.190235}},
+		{filter{3, 6, 2, 10}, quantizer{-0.124877, 0.0296483, 0.139239}},
+		{filter{2, 1, 1, 14}, quantizer{-0.101475, 0.0225617, 0.231971}},
+		{filter{3, 5, 6, 4}, quantizer{-0.0799915, -0.00729616, 0.063262}},
+		{filter{1, 9, 2, 12}, quantizer{-0.272556, 0.019424, 0.302559}},
+		{filter{3, 4, 2, 14}, quantizer{-0.164292, -0.0321188, 0.08463}},
+	}
+}
+
+type classifier struct {
+	filter    filter
+	quantizer quantizer
+}
+
+func (c classifier) classify(img integralImage, offset int) int {
+	return c.quantizer.quantize(c.filter.apply(img, offset))
+}
+
+// filter compares the energy of areas of the image, spanning width blocks from the offset and height notes from y.
+type filter struct {
+	kind, y, height, width int
+}
+
+//nolint:mnd // halves & thirds of the filter
+func (f filter) apply(img integralImage, x int) float64 {
+	y, w, h := f.y, f.width, f.height
+	var a, b float64
+	switch f.kind {
+	case 0:
+		// The whole area
+		a = img.area(x, y, x+w, y+h)
+	case 1:
+		// Upper notes against lower notes
+		a = img.area(x, y+h/2, x+w, y+h)
+		b = img.area(x, y, x+w, y+h/2)
+	case 2:
+		// Later blocks against earlier blocks
+		a = img.area(x+w/2, y, x+w, y+h)
+		b = img.area(x, y, x+w/2, y+h)
+	case 3:
+		// Diagonal quarters against each other
+		a = img.area(x, y+h/2, x+w/2, y+h) + img.area(x+w/2, y, x+w, y+h/2)
+		b = img.area(x, y, x+w/2, y+h/2) + img.area(x+w/2, y+h/2, x+w, y+h)
+	case 4:
+		// The middle third of the notes against the outer thirds
+		third := h / 3
+		a = img.area(x, y+third, x+w, y+2*third)
+		b = img.area(x, y, x+w, y+third) + img.area(x, y+2*third, x+w, y+h)
+	case 5:
+		// The middle third of the blocks against the outer thirds
+		third := w / 3
+		a = img.area(x+third, y, x+2*third, y+h)
+		b = img.area(x, y, x+third, y+h) + img.area(x+2*third, y, x+w, y+h)
+	}
+	return math.Log1p(a) - math.Log1p(b)
+}
+
+// quantizer splits the output of a filter into 4 values at the thresholds.
+type quantizer struct {
+	t0, t1, t2 float64
+}
+
+//nolint:mnd // quantized values
+func (q quantizer) quantize(v float64) int {
+	switch {
+	case v < q.t0:
+		return 0
+	case v < q.t1:
+		return 1
+	case v < q.t2:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// integralImage holds the sum of every note of every block up to each point, so the sum of any area can be found from
+// its corners.
+type integralImage [][notes + 1]float64
+
+func newIntegralImage(image [][notes]float64) integralImage {
+	img := make(integralImage, len(image)+1)
+	for x, row := range image {
+		for y, v := range row {
+			img[x+1][y+1] = v + img[x][y+1] + img[x+1][y] - img[x][y]
+		}
+	}
+	return img
+}
+
+func (img integralImage) rows() int {
+	return len(img) - 1
+}
+
+// area is the sum of the blocks from x1 up to x2 of the notes from y1 up to y2.
+func (img integralImage) area(x1, y1, x2, y2 int) float64 {
+	return img[x2][y2] - img[x1][y2] - img[x2][y1] + img[x1][y1]
+}
@@ -0,0 +1,111 @@
+package chromaprint
+
+import (
+	"math"
+)
+
+const (
+	// cutoff is the share of the Nyquist frequency of the output the low pass filter starts at, as with Chromaprint.
+	cutoff = 0.8
+	// filterLength is the number of output samples the filter spans, as with Chromaprint.
+	filterLength = 16
+	// phases is the number of fractional positions between input samples the filter is calculated for.
+	phases = 1024
+	// kaiserBeta is the shape of the Kaiser window of the filter.
+	kaiserBeta = 9
+)
+
+// resampler converts audio to a lower sample rate with a windowed sinc low pass filter.
+type resampler struct {
+	// step is the number of input samples between each output sample
+	step float64
+	// half is the number of input samples either side of an output sample the filter covers
+	half int
+	// filters are the taps of the filter for each phase
+	filters [][]float64
+	// in is the input still needed by the filter, starting at input sample offset
+	in     []float64
+	offset int64
+	// next is the index of the next output sample
+	next int64
+}
+
+func newResampler(from, to int) *resampler {
+	if from == to {
+		return &resampler{step: 1, filters: [][]float64{{1}}}
+	}
+
+	step := float64(from) / float64(to)
+	// The filter is only stretched when downsampling, to cut off above the Nyquist frequency of the output
+	factor := min(1, 1/step) * cutoff
+	half := int(math.Ceil(filterLength / factor / 2)) //nolint:mnd // either side of the output sample
+
+	r := &resampler{step: step, half: half, filters: make([][]float64, phases)}
+	for p := range r.filters {
+		taps := make([]float64, 2*half+1) //nolint:mnd // either side of the output sample
+		var sum float64
+		for i := range taps {
+			x := float64(i-half) - float64(p)/phases
+			taps[i] = sinc(x*factor) * kaiser(x/float64(half+1))
+			sum += taps[i]
+		}
+		// Normalising keeps the level of the audio the same
+		for i := range taps {
+			taps[i] /= sum
+		}
+		r.filters[p] = taps
+	}
+	return r
+}
+
+// write adds an input sample, calling emit with every output sample it completes.
+func (r *resampler) write(sample float64, emit func(float64)) {
+	r.in = append(r.in, sample)
+
+	for {
+		pos := float64(r.next) * r.step
+		first := int64(math.Floor(pos)) - int64(r.half)
+		if first+int64(2*r.half) >= r.offset+int64(len(r.in)) { //nolint:mnd // either side of the output sample
+			break
+		}
+
+		taps := r.filters[int((pos-math.Floor(pos))*phases)]
+		var out float64
+		for i, tap := range taps {
+			// Input before the start of the audio is silence
+			if j := first + int64(i) - r.offset; j >= 0 {
+				out += r.in[j] * tap
+			}
+		}
+		emit(out)
+		r.next++
+
+		// Drop input which no later output sample needs
+		if drop := min(int64(math.Floor(float64(r.next)*r.step))-int64(r.half)-r.offset, int64(len(r.in))); drop > 0 {
+			r.in = r.in[drop:]
+			r.offset += drop
+		}
+	}
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// kaiser is the Kaiser window, for x from -1 to 1.
+func kaiser(x float64) float64 {
+	return bessel(kaiserBeta*math.Sqrt(max(0, 1-x*x))) / bessel(kaiserBeta)
+}
+
+// bessel is the zeroth order modified Bessel function of the first kind.
+func bessel(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1.0; term > 1e-12*sum; k++ {
+		term *= (x / (2 * k)) * (x / (2 * k)) //nolint:mnd // series expansion
+		sum += term
+	}
+	return sum
+}
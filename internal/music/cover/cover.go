@@ -0,0 +1,99 @@
+// Package cover checks embedded cover art against a quality policy, and fixes cover art which doesn't meet it.
+package cover
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	_ "image/png" // decode PNG cover art
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+// DefaultQuality is the JPEG quality cover art is recompressed at.
+const DefaultQuality = 90
+
+// Policy is the rules embedded cover art has to meet, where zero means no limit.
+type Policy struct {
+	// MinSize is the minimum width & height, in pixels.
+	MinSize int
+	// MaxSize is the maximum width & height, in pixels.
+	MaxSize int
+	// MaxBytes is the maximum size of the image data.
+	MaxBytes int
+	// Square is whether the width & height have to be the same.
+	Square bool
+	// Quality is the JPEG quality used when fixing cover art.
+	Quality int
+}
+
+// IsZero is whether the policy doesn't have any rules, ignoring the quality only used when fixing cover art.
+func (p Policy) IsZero() bool {
+	return p.MinSize == 0 && p.MaxSize == 0 && p.MaxBytes == 0 && !p.Square
+}
+
+// Validate checks the image data against the policy. Only JPEG & PNG images can be checked.
+func (p Policy) Validate(data []byte) []error {
+	if p.IsZero() {
+		return nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return []error{UnsupportedFormatError{MIME: http.DetectContentType(data)}}
+	}
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	if p.MinSize > 0 && (config.Width < p.MinSize || config.Height < p.MinSize) {
+		errs = append(errs, TooSmallError{Width: config.Width, Height: config.Height, MinSize: p.MinSize})
+	}
+	if p.MaxSize > 0 && (config.Width > p.MaxSize || config.Height > p.MaxSize) {
+		errs = append(errs, TooLargeError{Width: config.Width, Height: config.Height, MaxSize: p.MaxSize})
+	}
+	if p.MaxBytes > 0 && len(data) > p.MaxBytes {
+		errs = append(errs, TooManyBytesError{Bytes: len(data), MaxBytes: p.MaxBytes})
+	}
+	if p.Square && config.Width != config.Height {
+		errs = append(errs, NotSquareError{Width: config.Width, Height: config.Height})
+	}
+
+	return errs
+}
+
+// Fix downscales the image to fit within the maximum size, recompressing it as a JPEG.
+func (p Policy) Fix(data []byte) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	if width, height := bounds.Dx(), bounds.Dy(); p.MaxSize > 0 && (width > p.MaxSize || height > p.MaxSize) {
+		// Keep the aspect ratio, with the longest side at the maximum size
+		if width >= height {
+			width, height = p.MaxSize, max(1, height*p.MaxSize/width)
+		} else {
+			width, height = max(1, width*p.MaxSize/height), p.MaxSize
+		}
+		scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+		img = scaled
+	}
+
+	quality := p.Quality
+	if quality == 0 {
+		quality = DefaultQuality
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,100 @@
+package cover
+
+import "fmt"
+
+var _ error = TooSmallError{}
+
+type TooSmallError struct {
+	Width   int
+	Height  int
+	MinSize int
+}
+
+func (e TooSmallError) Error() string {
+	return fmt.Sprintf("cover art is %dx%d, smaller than the minimum of %d", e.Width, e.Height, e.MinSize)
+}
+
+func (e TooSmallError) Is(err error) bool {
+	e2, ok := err.(TooSmallError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = TooLargeError{}
+
+type TooLargeError struct {
+	Width   int
+	Height  int
+	MaxSize int
+}
+
+func (e TooLargeError) Error() string {
+	return fmt.Sprintf("cover art is %dx%d, larger than the maximum of %d", e.Width, e.Height, e.MaxSize)
+}
+
+func (e TooLargeError) Is(err error) bool {
+	e2, ok := err.(TooLargeError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = TooManyBytesError{}
+
+type TooManyBytesError struct {
+	Bytes    int
+	MaxBytes int
+}
+
+func (e TooManyBytesError) Error() string {
+	return fmt.Sprintf("cover art is %d bytes, more than the maximum of %d", e.Bytes, e.MaxBytes)
+}
+
+func (e TooManyBytesError) Is(err error) bool {
+	e2, ok := err.(TooManyBytesError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = NotSquareError{}
+
+type NotSquareError struct {
+	Width  int
+	Height int
+}
+
+func (e NotSquareError) Error() string {
+	return fmt.Sprintf("cover art is %dx%d, rather than square", e.Width, e.Height)
+}
+
+func (e NotSquareError) Is(err error) bool {
+	e2, ok := err.(NotSquareError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = UnsupportedFormatError{}
+
+// UnsupportedFormatError is cover art in a format which can't be checked against the policy.
+type UnsupportedFormatError struct {
+	MIME string
+}
+
+func (e UnsupportedFormatError) Error() string {
+	return fmt.Sprintf("cover art is %s, which can't be checked against the cover policy", e.MIME)
+}
+
+func (e UnsupportedFormatError) Is(err error) bool {
+	e2, ok := err.(UnsupportedFormatError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
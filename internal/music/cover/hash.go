@@ -0,0 +1,192 @@
+package cover
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+	"slices"
+	"sync"
+
+	"golang.org/x/image/draw"
+)
+
+// DefaultMaxDistance is the default largest distance between the hashes of two images for them to be treated as the
+// same image, allowing for differences from resizing or re-encoding.
+const DefaultMaxDistance = 10
+
+// Hash is a perceptual hash of an image, where similar images have hashes differing by only a few bits.
+type Hash uint64
+
+// Distance returns the number of bits which differ between the hashes.
+func (h Hash) Distance(other Hash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+// Algorithm is a way of calculating the perceptual hash of an image.
+type Algorithm string
+
+const (
+	// DifferenceHashAlgorithm is a dHash, see DifferenceHash.
+	DifferenceHashAlgorithm Algorithm = "dhash"
+	// PerceptualHashAlgorithm is a pHash, see PerceptualHash.
+	PerceptualHashAlgorithm Algorithm = "phash"
+)
+
+// ParseAlgorithm returns the algorithm with the given name.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch a := Algorithm(name); a {
+	case DifferenceHashAlgorithm, PerceptualHashAlgorithm:
+		return a, nil
+	default:
+		return "", fmt.Errorf("unknown hash algorithm %q", name)
+	}
+}
+
+// Hash calculates the hash of the image using the algorithm.
+func (a Algorithm) Hash(data []byte) (Hash, error) {
+	if a == PerceptualHashAlgorithm {
+		return PerceptualHash(data)
+	}
+	return DifferenceHash(data)
+}
+
+const (
+	differenceHashWidth  = 9
+	differenceHashHeight = 8
+)
+
+// DifferenceHash calculates the dHash of an image - whether each pixel is brighter than the next, once the image is
+// shrunk to 9x8 & converted to greyscale.
+func DifferenceHash(data []byte) (Hash, error) {
+	small, err := shrink(data, differenceHashWidth, differenceHashHeight)
+	if err != nil {
+		return 0, err
+	}
+
+	var h Hash
+	for y := range differenceHashHeight {
+		for x := range differenceHashWidth - 1 {
+			h <<= 1
+			if small.GrayAt(x, y).Y > small.GrayAt(x+1, y).Y {
+				h |= 1
+			}
+		}
+	}
+
+	return h, nil
+}
+
+const (
+	perceptualHashSize    = 32
+	perceptualHashLowSize = 8
+)
+
+// PerceptualHash calculates the pHash of an image - whether each of the lowest frequencies of the discrete cosine
+// transform is above the median, once the image is shrunk to 32x32 & converted to greyscale.
+func PerceptualHash(data []byte) (Hash, error) {
+	small, err := shrink(data, perceptualHashSize, perceptualHashSize)
+	if err != nil {
+		return 0, err
+	}
+
+	pixels := make([][]float64, perceptualHashSize)
+	for y := range perceptualHashSize {
+		pixels[y] = make([]float64, perceptualHashSize)
+		for x := range perceptualHashSize {
+			pixels[y][x] = float64(small.GrayAt(x, y).Y)
+		}
+	}
+
+	freqs := dct(pixels)
+
+	low := make([]float64, 0, perceptualHashLowSize*perceptualHashLowSize)
+	for v := range perceptualHashLowSize {
+		low = append(low, freqs[v][:perceptualHashLowSize]...)
+	}
+
+	// The first value is the average brightness, which would skew the median
+	sorted := slices.Clone(low[1:])
+	slices.Sort(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2 //nolint:mnd // middle of an even number of values
+
+	var h Hash
+	for _, f := range low {
+		h <<= 1
+		if f > median {
+			h |= 1
+		}
+	}
+
+	return h, nil
+}
+
+// dct is the two dimensional discrete cosine transform (DCT-II) of a square matrix.
+func dct(in [][]float64) [][]float64 {
+	n := len(in)
+	cosines := make([][]float64, n)
+	for k := range n {
+		cosines[k] = make([]float64, n)
+		for i := range n {
+			cosines[k][i] = math.Cos(math.Pi / float64(n) * (float64(i) + 0.5) * float64(k)) //nolint:mnd // DCT-II
+		}
+	}
+
+	rows := make([][]float64, n)
+	for y := range n {
+		rows[y] = make([]float64, n)
+		for u := range n {
+			for x := range n {
+				rows[y][u] += in[y][x] * cosines[u][x]
+			}
+		}
+	}
+
+	out := make([][]float64, n)
+	for v := range n {
+		out[v] = make([]float64, n)
+		for u := range n {
+			for y := range n {
+				out[v][u] += rows[y][u] * cosines[v][y]
+			}
+		}
+	}
+
+	return out
+}
+
+// shrink decodes the image and scales it to a greyscale image of the given size.
+func shrink(data []byte, width, height int) (*image.Gray, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	small := image.NewGray(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	return small, nil
+}
+
+// HashCache caches the hashes of images by a key, such as the URL of the image, so each image is only fetched &
+// hashed once.
+type HashCache struct {
+	hashes sync.Map
+}
+
+// Get returns the hash cached for the key, calculating it with the given function if it isn't cached.
+func (c *HashCache) Get(key string, hash func() (Hash, error)) (Hash, error) {
+	if h, ok := c.hashes.Load(key); ok {
+		return h.(Hash), nil //nolint:forcetypeassert // only hashes are stored
+	}
+
+	h, err := hash()
+	if err != nil {
+		return 0, err
+	}
+
+	c.hashes.Store(key, h)
+
+	return h, nil
+}
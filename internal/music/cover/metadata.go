@@ -0,0 +1,69 @@
+package cover
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+)
+
+// Metadata is the description of an image stored alongside it in a FLAC PICTURE block.
+type Metadata struct {
+	MIME   string
+	Width  uint32
+	Height uint32
+	// ColorDepth is the bits per pixel.
+	ColorDepth uint32
+	// IndexedColors is the number of colours in the palette of indexed colour images, otherwise zero.
+	IndexedColors uint32
+}
+
+const (
+	// pngBitDepthOffset is the offset of the bit depth, followed by the colour type, within the IHDR chunk of a PNG.
+	pngBitDepthOffset = 24
+	pngPalette        = 3
+	// paletteDepth is the bits per pixel of palette entries, which are always 8 bit RGB.
+	paletteDepth = 24
+	jpegDepth    = 8
+)
+
+// ReadMetadata decodes the header of the JPEG or PNG image to work out its metadata, in the same way as metaflac.
+func ReadMetadata(data []byte) (Metadata, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	m := Metadata{
+		MIME:   "image/" + format,
+		Width:  uint32(config.Width),  //nolint:gosec // image dimensions are at most 32 bits
+		Height: uint32(config.Height), //nolint:gosec // image dimensions are at most 32 bits
+	}
+
+	switch format {
+	case "png":
+		bitDepth, colorType := uint32(data[pngBitDepthOffset]), data[pngBitDepthOffset+1]
+		if colorType == pngPalette {
+			m.ColorDepth = paletteDepth
+			m.IndexedColors = 1 << bitDepth
+		} else {
+			//nolint:mnd // channels for each PNG colour type
+			m.ColorDepth = bitDepth * map[byte]uint32{0: 1, 2: 3, 4: 2, 6: 4}[colorType]
+		}
+	case "jpeg":
+		switch config.ColorModel {
+		case color.GrayModel:
+			m.ColorDepth = jpegDepth
+		case color.CMYKModel:
+			m.ColorDepth = 4 * jpegDepth //nolint:mnd // CMYK components
+		default:
+			m.ColorDepth = 3 * jpegDepth //nolint:mnd // YCbCr components
+		}
+	default:
+		return Metadata{}, errUnsupportedFormat
+	}
+
+	return m, nil
+}
+
+var errUnsupportedFormat = errors.New("unsupported image format")
@@ -0,0 +1,83 @@
+package cover
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-flac/flacpicture/v2"
+)
+
+// typeNames are the names of the FLAC picture types, as used on the command line & in logs.
+func typeNames() map[flacpicture.PictureType]string {
+	return map[flacpicture.PictureType]string{
+		flacpicture.PictureTypeOther:                   "other",
+		flacpicture.PictureTypeFileIcon:                "file-icon",
+		flacpicture.PictureTypeOtherIcon:               "other-file-icon",
+		flacpicture.PictureTypeFrontCover:              "front-cover",
+		flacpicture.PictureTypeBackCover:               "back-cover",
+		flacpicture.PictureTypeLeaflet:                 "leaflet",
+		flacpicture.PictureTypeMedia:                   "media",
+		flacpicture.PictureTypeLeadArtist:              "lead-artist",
+		flacpicture.PictureTypeArtist:                  "artist",
+		flacpicture.PictureTypeConductor:               "conductor",
+		flacpicture.PictureTypeBand:                    "band",
+		flacpicture.PictureTypeComposer:                "composer",
+		flacpicture.PictureTypeLyricist:                "lyricist",
+		flacpicture.PictureTypeRecordingLocation:       "recording-location",
+		flacpicture.PictureTypeDuringRecording:         "during-recording",
+		flacpicture.PictureTypeDuringPerformance:       "during-performance",
+		flacpicture.PictureTypeScreenCapture:           "screen-capture",
+		flacpicture.PictureTypeBrightColouredFish:      "bright-coloured-fish",
+		flacpicture.PictureTypeIllustration:            "illustration",
+		flacpicture.PictureTypeBandArtistLogotype:      "band-logotype",
+		flacpicture.PictureTypePublisherStudioLogotype: "publisher-logotype",
+	}
+}
+
+// TypeName returns the name of the picture type.
+func TypeName(t flacpicture.PictureType) string {
+	if name, ok := typeNames()[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown-%d", t)
+}
+
+// ParseTypes parses the names of picture types.
+func ParseTypes(names []string) ([]flacpicture.PictureType, error) {
+	var types []flacpicture.PictureType
+	for _, name := range names {
+		t, ok := typeByName(name)
+		if !ok {
+			return nil, fmt.Errorf(
+				"unknown picture type %q, expected one of %s",
+				name, strings.Join(slices.Sorted(maps.Values(typeNames())), ","),
+			)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// CoverArtArchiveType returns the type of image in the Cover Art Archive which matches the picture type, if any.
+// https://musicbrainz.org/doc/Cover_Art/Types
+func CoverArtArchiveType(t flacpicture.PictureType) (string, bool) {
+	//nolint:exhaustive // not all picture types are in the Cover Art Archive
+	v, ok := map[flacpicture.PictureType]string{
+		flacpicture.PictureTypeFrontCover: "Front",
+		flacpicture.PictureTypeBackCover:  "Back",
+		flacpicture.PictureTypeLeaflet:    "Booklet",
+		flacpicture.PictureTypeMedia:      "Medium",
+	}[t]
+	return v, ok
+}
+
+func typeByName(name string) (flacpicture.PictureType, bool) {
+	for t, n := range typeNames() {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
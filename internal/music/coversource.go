@@ -0,0 +1,202 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/wjam/flac-check/internal/coverart"
+	"github.com/wjam/flac-check/internal/itunes"
+	"github.com/wjam/flac-check/internal/musicbrainz"
+	"github.com/wjam/flac-check/internal/wikipedia"
+)
+
+// CoverSource is somewhere to find the front cover of an album for tracks without one.
+type CoverSource interface {
+	// FrontCover returns the front cover of the album, or false if the source doesn't have one.
+	FrontCover(ctx context.Context, album CoverQuery) (Cover, bool, error)
+}
+
+// CoverQuery describes the album to find the front cover of.
+type CoverQuery struct {
+	Release musicbrainz.Release
+	Artist  string
+	Album   string
+}
+
+// Cover is a front cover found by a cover source.
+type Cover struct {
+	// URL is where the cover was found, which may be a local file
+	URL  string
+	Data []byte
+}
+
+const (
+	ReleaseCoverSource      = "release"
+	ReleaseGroupCoverSource = "release-group"
+	WikipediaCoverSource    = "wikipedia"
+	ITunesCoverSource       = "itunes"
+	LocalCoverSource        = "local"
+)
+
+// CoverSourceNames returns the names of every cover source.
+func CoverSourceNames() []string {
+	return []string{
+		ReleaseCoverSource, ReleaseGroupCoverSource, WikipediaCoverSource, ITunesCoverSource, LocalCoverSource,
+	}
+}
+
+// DefaultCoverSources returns the names of the cover sources used by default, in the order they're tried.
+func DefaultCoverSources() []string {
+	return []string{ReleaseCoverSource, ReleaseGroupCoverSource, WikipediaCoverSource}
+}
+
+var _ CoverSource = releaseCoverSource{}
+
+// releaseCoverSource is the front cover of the release in the Cover Art Archive.
+type releaseCoverSource struct {
+	art *coverart.Client
+}
+
+func (s releaseCoverSource) FrontCover(ctx context.Context, album CoverQuery) (Cover, bool, error) {
+	if album.Release.CoverArtArchive.Count == 0 {
+		return Cover{}, false, nil
+	}
+
+	url, err := s.art.GetCoverArtFromMusicBrainzReleaseID(ctx, album.Release.ID)
+	if err != nil {
+		return Cover{}, false, err
+	}
+
+	return fetchCover(ctx, s.art, url)
+}
+
+var _ CoverSource = releaseGroupCoverSource{}
+
+// releaseGroupCoverSource is the front cover of the release group in the Cover Art Archive, which comes from one of
+// the releases in the group.
+type releaseGroupCoverSource struct {
+	art *coverart.Client
+}
+
+func (s releaseGroupCoverSource) FrontCover(ctx context.Context, album CoverQuery) (Cover, bool, error) {
+	if album.Release.ReleaseGroup.ID == "" {
+		return Cover{}, false, nil
+	}
+
+	data, url, err := s.art.FetchReleaseGroupFrontCover(ctx, album.Release.ReleaseGroup.ID)
+	if err != nil || data == nil {
+		return Cover{}, false, err
+	}
+
+	return Cover{URL: url, Data: data}, true, nil
+}
+
+var _ CoverSource = wikipediaCoverSource{}
+
+// wikipediaCoverSource is the album cover from the Wikipedia articles about the release group.
+type wikipediaCoverSource struct {
+	wiki *wikipedia.Client
+	art  *coverart.Client
+}
+
+func (s wikipediaCoverSource) FrontCover(ctx context.Context, album CoverQuery) (Cover, bool, error) {
+	url, err := s.wiki.GetCoverArtFromMusicBrainzReleaseID(ctx, album.Release.ID)
+	if err != nil {
+		return Cover{}, false, err
+	}
+
+	return fetchCover(ctx, s.art, url)
+}
+
+var _ CoverSource = iTunesCoverSource{}
+
+// iTunesCoverSource is the artwork of the album with the same artist & title in the iTunes store.
+type iTunesCoverSource struct {
+	itunes *itunes.Client
+	art    *coverart.Client
+}
+
+func (s iTunesCoverSource) FrontCover(ctx context.Context, album CoverQuery) (Cover, bool, error) {
+	if album.Artist == "" || album.Album == "" {
+		return Cover{}, false, nil
+	}
+
+	url, err := s.itunes.GetCoverArtForAlbum(ctx, album.Artist, album.Album)
+	if err != nil {
+		return Cover{}, false, err
+	}
+
+	return fetchCover(ctx, s.art, url)
+}
+
+var _ CoverSource = localCoverSource{}
+
+// localCoverSource is a directory of manually sourced covers, named after the MusicBrainz release or release group
+// ID, such as 76df3287-6cda-33eb-8e9a-044b5e15ffdd.jpg.
+type localCoverSource struct {
+	dir string
+}
+
+func (s localCoverSource) FrontCover(_ context.Context, album CoverQuery) (Cover, bool, error) {
+	if s.dir == "" {
+		return Cover{}, false, nil
+	}
+
+	for _, id := range []string{album.Release.ID, album.Release.ReleaseGroup.ID} {
+		if id == "" {
+			continue
+		}
+		for _, ext := range []string{".jpg", ".png"} {
+			path := filepath.Join(s.dir, id+ext)
+			data, err := os.ReadFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return Cover{}, false, err
+			}
+			return Cover{URL: path, Data: data}, true, nil
+		}
+	}
+
+	return Cover{}, false, nil
+}
+
+func fetchCover(ctx context.Context, art *coverart.Client, url string) (Cover, bool, error) {
+	if url == "" {
+		return Cover{}, false, nil
+	}
+
+	data, err := art.FetchImage(ctx, url)
+	if err != nil {
+		return Cover{}, false, err
+	}
+
+	return Cover{URL: url, Data: data}, true, nil
+}
+
+// coverSources creates the cover sources with the given names, in the same order.
+func (s *Scan) coverSources(names []string) ([]CoverSource, error) {
+	var sources []CoverSource
+	for _, name := range names {
+		switch name {
+		case ReleaseCoverSource:
+			sources = append(sources, releaseCoverSource{art: s.art})
+		case ReleaseGroupCoverSource:
+			sources = append(sources, releaseGroupCoverSource{art: s.art})
+		case WikipediaCoverSource:
+			sources = append(sources, wikipediaCoverSource{wiki: s.wiki, art: s.art})
+		case ITunesCoverSource:
+			sources = append(sources, iTunesCoverSource{itunes: s.itunes, art: s.art})
+		case LocalCoverSource:
+			sources = append(sources, localCoverSource{dir: s.opts.CoverDir})
+		default:
+			return nil, fmt.Errorf("unknown cover source %q", name)
+		}
+	}
+	return sources, nil
+}
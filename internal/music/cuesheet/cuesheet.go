@@ -0,0 +1,201 @@
+// Package cuesheet parses FLAC CUESHEET metadata blocks.
+// https://www.rfc-editor.org/rfc/rfc9639.html#name-cuesheet
+package cuesheet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/go-flac/go-flac/v2"
+)
+
+const (
+	catalogNumberLength = 128
+	isrcLength          = 12
+	headerReserved      = 258
+	trackReserved       = 13
+	indexReserved       = 3
+	upcLength           = 12
+	eanLength           = 13
+
+	// LeadOutTrackNumber is the track number used by CD-DA cuesheets for the lead-out track.
+	LeadOutTrackNumber = 170
+)
+
+type CueSheet struct {
+	MediaCatalogNumber string
+	LeadInSamples      uint64
+	IsCompactDisc      bool
+	Tracks             []Track
+}
+
+type Track struct {
+	Offset      uint64
+	Number      uint8
+	ISRC        string
+	IsAudio     bool
+	PreEmphasis bool
+	Indices     []Index
+}
+
+type Index struct {
+	Offset uint64
+	Number uint8
+}
+
+// LeadOut returns the lead-out track, which is always the last track in a cuesheet.
+func (c *CueSheet) LeadOut() (Track, bool) {
+	if len(c.Tracks) == 0 {
+		return Track{}, false
+	}
+	last := c.Tracks[len(c.Tracks)-1]
+	if c.IsCompactDisc && last.Number != LeadOutTrackNumber {
+		return Track{}, false
+	}
+	return last, true
+}
+
+// AudioTracks returns all tracks except the lead-out track.
+func (c *CueSheet) AudioTracks() []Track {
+	if _, ok := c.LeadOut(); !ok {
+		return c.Tracks
+	}
+	return c.Tracks[:len(c.Tracks)-1]
+}
+
+// Track returns the track with the given track number.
+func (c *CueSheet) Track(number int) (Track, bool) {
+	for _, t := range c.AudioTracks() {
+		if int(t.Number) == number {
+			return t, true
+		}
+	}
+	return Track{}, false
+}
+
+// HasMediaCatalogNumber returns whether the cuesheet has a media catalog number - an all zero value means not set.
+func (c *CueSheet) HasMediaCatalogNumber() bool {
+	return strings.Trim(c.MediaCatalogNumber, "0") != ""
+}
+
+// ValidMediaCatalogNumber checks the media catalog number is a UPC-A or EAN-13 barcode with a valid check digit.
+func (c *CueSheet) ValidMediaCatalogNumber() bool {
+	mcn := c.MediaCatalogNumber
+	if len(mcn) != upcLength && len(mcn) != eanLength {
+		return false
+	}
+
+	sum := 0
+	for i, r := range mcn {
+		if r < '0' || r > '9' {
+			return false
+		}
+		digit := int(r - '0')
+		// Weights alternate 1 & 3 from the check digit leftwards, with the check digit itself weighted 1
+		if (len(mcn)-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	return sum%10 == 0
+}
+
+// StartOffset returns the offset, in samples, of index point 1 of the track - where the track actually starts.
+func (t Track) StartOffset() uint64 {
+	for _, idx := range t.Indices {
+		if idx.Number == 1 {
+			return t.Offset + idx.Offset
+		}
+	}
+	return t.Offset
+}
+
+func ParseFromMetaDataBlock(meta flac.MetaDataBlock) (*CueSheet, error) {
+	if meta.Type != flac.CueSheet {
+		return nil, ErrNotCueSheet
+	}
+
+	r := bytes.NewReader(meta.Data)
+
+	var header struct {
+		CatalogNumber [catalogNumberLength]byte
+		LeadInSamples uint64
+		Flags         uint8
+		Reserved      [headerReserved]byte
+		TrackCount    uint8
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	c := &CueSheet{
+		MediaCatalogNumber: trimNul(header.CatalogNumber[:]),
+		LeadInSamples:      header.LeadInSamples,
+		IsCompactDisc:      header.Flags&0x80 != 0,
+		Tracks:             make([]Track, 0, header.TrackCount),
+	}
+
+	for range header.TrackCount {
+		t, err := parseTrack(r)
+		if err != nil {
+			return nil, err
+		}
+		c.Tracks = append(c.Tracks, t)
+	}
+
+	return c, nil
+}
+
+func parseTrack(r io.Reader) (Track, error) {
+	var header struct {
+		Offset     uint64
+		Number     uint8
+		ISRC       [isrcLength]byte
+		Flags      uint8
+		Reserved   [trackReserved]byte
+		IndexCount uint8
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return Track{}, unexpectedEOF(err)
+	}
+
+	t := Track{
+		Offset:      header.Offset,
+		Number:      header.Number,
+		ISRC:        trimNul(header.ISRC[:]),
+		IsAudio:     header.Flags&0x80 == 0,
+		PreEmphasis: header.Flags&0x40 != 0,
+		Indices:     make([]Index, 0, header.IndexCount),
+	}
+
+	for range header.IndexCount {
+		var index struct {
+			Offset   uint64
+			Number   uint8
+			Reserved [indexReserved]byte
+		}
+		if err := binary.Read(r, binary.BigEndian, &index); err != nil {
+			return Track{}, unexpectedEOF(err)
+		}
+		t.Indices = append(t.Indices, Index{Offset: index.Offset, Number: index.Number})
+	}
+
+	return t, nil
+}
+
+func trimNul(b []byte) string {
+	return string(bytes.TrimRight(b, "\x00"))
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+var ErrNotCueSheet = errors.New("not a cuesheet metadata block")
@@ -0,0 +1,280 @@
+package cuesheet
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	// framesPerSecond is the number of CD frames, or sectors, per second used by the MSF timestamps of a cue sheet.
+	framesPerSecond  = 75
+	secondsPerMinute = 60
+	leadInSeconds    = 2
+	// compactDiscSampleRate is the sample rate of CD-DA audio, the only audio a CD-DA cuesheet can describe.
+	compactDiscSampleRate = 44100
+	// nonCompactDiscLeadOutTrackNumber is the track number used by non CD-DA cuesheets for the lead-out track.
+	nonCompactDiscLeadOutTrackNumber = 255
+)
+
+// Sheet is a cue sheet text file, as written by CD ripping software alongside a single file rip of a whole CD.
+type Sheet struct {
+	Catalog   string
+	Performer string
+	Title     string
+	Genre     string
+	Date      string
+	File      string
+	Tracks    []SheetTrack
+}
+
+type SheetTrack struct {
+	Number      int
+	Title       string
+	Performer   string
+	ISRC        string
+	IsAudio     bool
+	PreEmphasis bool
+	Indices     []SheetIndex
+}
+
+type SheetIndex struct {
+	Number int
+	// Frames is the position of the index point, in CD frames, from the start of the file.
+	Frames int
+}
+
+// ParseSheet parses the text of a cue sheet. Cue sheets are frequently not UTF-8, so anything which isn't valid
+// UTF-8 is assumed to be Windows-1252.
+func ParseSheet(data []byte) (*Sheet, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		var err error
+		data, err = charmap.Windows1252.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Sheet{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := splitFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if err := s.parseCommand(strings.ToUpper(fields[0]), fields[1:]); err != nil {
+			return nil, fmt.Errorf("cue sheet line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(s.Tracks) == 0 {
+		return nil, ErrNoSheetTracks
+	}
+	for _, t := range s.Tracks {
+		if _, ok := t.start(); !ok {
+			return nil, fmt.Errorf("cue sheet track %d doesn't have an INDEX 01", t.Number)
+		}
+	}
+
+	return s, nil
+}
+
+//nolint:cyclop // one case per command
+func (s *Sheet) parseCommand(command string, args []string) error {
+	var track *SheetTrack
+	if len(s.Tracks) > 0 {
+		track = &s.Tracks[len(s.Tracks)-1]
+	}
+
+	switch command {
+	case "CATALOG":
+		s.Catalog = arg(args, 0)
+	case "REM":
+		switch strings.ToUpper(arg(args, 0)) {
+		case "GENRE":
+			s.Genre = arg(args, 1)
+		case "DATE":
+			s.Date = arg(args, 1)
+		}
+	case "FILE":
+		if s.File != "" {
+			return ErrMultipleSheetFiles
+		}
+		s.File = arg(args, 0)
+	case "TRACK":
+		number, err := strconv.Atoi(arg(args, 0))
+		if err != nil {
+			return fmt.Errorf("invalid track number: %w", err)
+		}
+		s.Tracks = append(s.Tracks, SheetTrack{Number: number, IsAudio: strings.EqualFold(arg(args, 1), "AUDIO")})
+	case "TITLE":
+		if track == nil {
+			s.Title = arg(args, 0)
+		} else {
+			track.Title = arg(args, 0)
+		}
+	case "PERFORMER":
+		if track == nil {
+			s.Performer = arg(args, 0)
+		} else {
+			track.Performer = arg(args, 0)
+		}
+	case "ISRC":
+		if track != nil {
+			track.ISRC = arg(args, 0)
+		}
+	case "FLAGS":
+		if track != nil {
+			track.PreEmphasis = containsFold(args, "PRE")
+		}
+	case "INDEX":
+		if track == nil {
+			return errors.New("INDEX outside of a TRACK")
+		}
+		index, err := parseIndex(args)
+		if err != nil {
+			return err
+		}
+		track.Indices = append(track.Indices, index)
+	}
+
+	return nil
+}
+
+// CueSheet converts the cue sheet into the CUESHEET metadata block model, for a file with the given sample rate and
+// total number of samples.
+func (s *Sheet) CueSheet(sampleRate int, totalSamples uint64) *CueSheet {
+	c := &CueSheet{
+		MediaCatalogNumber: s.Catalog,
+		IsCompactDisc:      sampleRate == compactDiscSampleRate,
+		Tracks:             make([]Track, 0, len(s.Tracks)+1),
+	}
+	leadOutNumber := uint8(nonCompactDiscLeadOutTrackNumber)
+	if c.IsCompactDisc {
+		c.LeadInSamples = leadInSeconds * compactDiscSampleRate
+		leadOutNumber = LeadOutTrackNumber
+	}
+
+	toSamples := func(frames int) uint64 {
+		return uint64(frames) * uint64(sampleRate) / framesPerSecond //nolint:gosec // positions are never negative
+	}
+
+	for _, t := range s.Tracks {
+		// The track starts at its first index point, with each index point relative to the start of the track
+		offset := toSamples(t.Indices[0].Frames)
+		track := Track{
+			Offset:      offset,
+			Number:      uint8(t.Number), //nolint:gosec // CD track numbers are at most 99
+			ISRC:        t.ISRC,
+			IsAudio:     t.IsAudio,
+			PreEmphasis: t.PreEmphasis,
+			Indices:     make([]Index, 0, len(t.Indices)),
+		}
+		for _, idx := range t.Indices {
+			track.Indices = append(track.Indices, Index{
+				Offset: toSamples(idx.Frames) - offset,
+				Number: uint8(idx.Number), //nolint:gosec // index numbers are at most 99
+			})
+		}
+		c.Tracks = append(c.Tracks, track)
+	}
+
+	c.Tracks = append(c.Tracks, Track{Offset: totalSamples, Number: leadOutNumber})
+
+	return c
+}
+
+// Track returns the cue sheet track with the given track number.
+func (s *Sheet) Track(number int) (SheetTrack, bool) {
+	for _, t := range s.Tracks {
+		if t.Number == number {
+			return t, true
+		}
+	}
+	return SheetTrack{}, false
+}
+
+func (t SheetTrack) start() (int, bool) {
+	for _, idx := range t.Indices {
+		if idx.Number == 1 {
+			return idx.Frames, true
+		}
+	}
+	return 0, false
+}
+
+func parseIndex(args []string) (SheetIndex, error) {
+	number, err := strconv.Atoi(arg(args, 0))
+	if err != nil {
+		return SheetIndex{}, fmt.Errorf("invalid index number: %w", err)
+	}
+
+	var minutes, seconds, frames int
+	if _, err := fmt.Sscanf(arg(args, 1), "%d:%d:%d", &minutes, &seconds, &frames); err != nil {
+		return SheetIndex{}, fmt.Errorf("invalid index position %q: %w", arg(args, 1), err)
+	}
+
+	return SheetIndex{
+		Number: number,
+		Frames: (minutes*secondsPerMinute+seconds)*framesPerSecond + frames,
+	}, nil
+}
+
+// splitFields splits a line of a cue sheet on whitespace, treating double-quoted values as a single field.
+func splitFields(line string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuotes, inField := false, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			inField = true
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\r'):
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+func arg(args []string, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+	return args[i]
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrNoSheetTracks      = errors.New("cue sheet doesn't have any tracks")
+	ErrMultipleSheetFiles = errors.New("cue sheet references multiple files")
+)
@@ -0,0 +1,125 @@
+// Package discid calculates MusicBrainz disc IDs from the table of contents of a CD.
+// https://musicbrainz.org/doc/Disc_ID_Calculation
+package discid
+
+import (
+	"crypto/sha1" //nolint:gosec // MusicBrainz disc IDs are defined as SHA-1
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/music/cuesheet"
+)
+
+const (
+	// CompactDiscSampleRate is the only sample rate a disc ID can be calculated for.
+	CompactDiscSampleRate = 44100
+	samplesPerSector      = 588
+	// leadIn is the standard 2 second lead-in before the first track on a CD.
+	leadIn = 150
+	// dataTrackGap is the gap between the last audio session and a data track on an enhanced CD.
+	dataTrackGap = 11400
+	maxTracks    = 99
+)
+
+// TOC is the table of contents of a CD, with all offsets in sectors.
+type TOC struct {
+	FirstTrack int
+	LastTrack  int
+	LeadOut    int
+	Offsets    []int
+}
+
+// FromTrackLengths builds the TOC of a CD from the length, in samples, of each track in order.
+func FromTrackLengths(samples []int64) (TOC, error) {
+	if len(samples) == 0 || len(samples) > maxTracks {
+		return TOC{}, fmt.Errorf("cannot calculate disc ID for %d tracks", len(samples))
+	}
+
+	toc := TOC{
+		FirstTrack: 1,
+		LastTrack:  len(samples),
+		Offsets:    make([]int, 0, len(samples)),
+	}
+
+	// Track positions are accumulated in samples so rounding doesn't drift across the disc
+	var position int64
+	for _, s := range samples {
+		if s <= 0 {
+			return TOC{}, errors.New("cannot calculate disc ID for track with unknown length")
+		}
+		toc.Offsets = append(toc.Offsets, toSector(position))
+		position += s
+	}
+	toc.LeadOut = toSector(position)
+
+	return toc, nil
+}
+
+// FromCueSheet builds the TOC of a CD from an embedded CD-DA cuesheet.
+func FromCueSheet(c *cuesheet.CueSheet) (TOC, error) {
+	if !c.IsCompactDisc {
+		return TOC{}, errors.New("cuesheet is not for a CD")
+	}
+
+	leadOut, ok := c.LeadOut()
+	if !ok {
+		return TOC{}, errors.New("cuesheet doesn't have a lead-out track")
+	}
+	tracks := c.AudioTracks()
+
+	leadOutSector := toSector(int64(leadOut.Offset)) //nolint:gosec // CD offsets are far below int64 max
+	// Enhanced CDs have a trailing data track which isn't part of the disc ID
+	if len(tracks) > 0 && !tracks[len(tracks)-1].IsAudio {
+		leadOutSector = toSector(int64(tracks[len(tracks)-1].StartOffset())) - dataTrackGap //nolint:gosec // as above
+		tracks = tracks[:len(tracks)-1]
+	}
+
+	if len(tracks) == 0 || len(tracks) > maxTracks {
+		return TOC{}, fmt.Errorf("cannot calculate disc ID for %d tracks", len(tracks))
+	}
+
+	toc := TOC{
+		FirstTrack: int(tracks[0].Number),
+		LastTrack:  int(tracks[len(tracks)-1].Number),
+		LeadOut:    leadOutSector,
+		Offsets:    make([]int, 0, len(tracks)),
+	}
+	for _, t := range tracks {
+		toc.Offsets = append(toc.Offsets, toSector(int64(t.StartOffset()))) //nolint:gosec // as above
+	}
+
+	return toc, nil
+}
+
+// ID returns the MusicBrainz disc ID for the TOC.
+func (t TOC) ID() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%02X%02X%08X", t.FirstTrack, t.LastTrack, t.LeadOut)
+	for track := 1; track <= maxTracks; track++ {
+		offset := 0
+		if i := track - t.FirstTrack; i >= 0 && i < len(t.Offsets) {
+			offset = t.Offsets[i]
+		}
+		_, _ = fmt.Fprintf(&b, "%08X", offset)
+	}
+
+	sum := sha1.Sum([]byte(b.String())) //nolint:gosec // MusicBrainz disc IDs are defined as SHA-1
+
+	return strings.NewReplacer("+", ".", "/", "_", "=", "-").Replace(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// String returns the TOC in the format used by the MusicBrainz `toc` query parameter.
+func (t TOC) String() string {
+	parts := []string{strconv.Itoa(t.FirstTrack), strconv.Itoa(t.LastTrack), strconv.Itoa(t.LeadOut)}
+	for _, o := range t.Offsets {
+		parts = append(parts, strconv.Itoa(o))
+	}
+	return strings.Join(parts, " ")
+}
+
+func toSector(samples int64) int {
+	return int((samples+samplesPerSector/2)/samplesPerSector) + leadIn
+}
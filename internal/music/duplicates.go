@@ -0,0 +1,106 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/logging"
+	"github.com/wjam/flac-check/internal/music/track"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+)
+
+// Duplicates finds tracks with exactly the same audio in different albums, such as the same rip filed under two
+// artists, so one copy can be picked to keep.
+type Duplicates struct {
+	path string
+}
+
+func NewDuplicates(path string) *Duplicates {
+	return &Duplicates{
+		path: path,
+	}
+}
+
+// duplicate is just enough of a track to report it, so the audio of the whole library isn't kept in memory.
+type duplicate struct {
+	path string
+	tags map[vorbis.Tag][]string
+}
+
+func (d *Duplicates) Run(ctx context.Context) error {
+	tracks := map[string][]duplicate{}
+	err := forEachTrack(ctx, d.path, func(ctx context.Context, t *track.Track) error {
+		sum, err := t.AudioMD5()
+		if err != nil || sum == "" {
+			return err
+		}
+		logging.FromContext(ctx).DebugContext(ctx, "Hashed audio", slog.String("md5", sum))
+
+		tags := map[vorbis.Tag][]string{}
+		for _, name := range t.TagNames() {
+			tags[name] = t.Tag(name)
+		}
+		tracks[sum] = append(tracks[sum], duplicate{path: t.Path(), tags: tags})
+		return nil
+	})
+
+	errs := []error{err}
+	for _, sum := range slices.Sorted(maps.Keys(tracks)) {
+		if err := duplicateAudio(sum, tracks[sum]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// duplicateAudio returns an error if the tracks with the same audio are in more than one album, along with the
+// differences between their tags.
+func duplicateAudio(sum string, tracks []duplicate) error {
+	albums := map[string]struct{}{}
+	for _, t := range tracks {
+		albums[filepath.Dir(t.path)] = struct{}{}
+	}
+	if len(albums) < 2 { //nolint:mnd // duplicates need at least two albums
+		return nil
+	}
+
+	slices.SortFunc(tracks, func(a, b duplicate) int {
+		return strings.Compare(a.path, b.path)
+	})
+
+	names := map[vorbis.Tag]struct{}{}
+	paths := make([]string, 0, len(tracks))
+	for _, t := range tracks {
+		paths = append(paths, t.path)
+		for name := range t.tags {
+			names[name] = struct{}{}
+		}
+	}
+
+	var differences []TagDifference
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		values := make([]string, 0, len(tracks))
+		for _, t := range tracks {
+			value := strings.Join(t.tags[name], ";")
+			if value == "" {
+				value = "<none>"
+			}
+			values = append(values, value)
+		}
+		if slices.ContainsFunc(values, func(v string) bool { return v != values[0] }) {
+			differences = append(differences, TagDifference{Tag: name, Values: values})
+		}
+	}
+
+	return DuplicateAudioError{
+		MD5:         sum,
+		Tracks:      paths,
+		Differences: differences,
+	}
+}
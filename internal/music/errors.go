@@ -1,11 +1,17 @@
 package music
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/wjam/flac-check/internal/music/cover"
 	"github.com/wjam/flac-check/internal/music/vorbis"
+
+	"github.com/go-flac/flacpicture/v2"
 )
 
 var _ error = NotSingleAlbumArtistError{}
@@ -152,6 +158,278 @@ func (e InvalidGenreTagError) Is(err error) bool {
 	return slices.Equal(e.Values, e2.Values)
 }
 
+var _ error = MissingReplayGainError{}
+
+type MissingReplayGainError struct {
+	Tracks []string
+}
+
+func (e MissingReplayGainError) Error() string {
+	return fmt.Sprintf("expected ReplayGain tags on every track of the album, missing from %s", join(e.Tracks))
+}
+
+func (e MissingReplayGainError) Is(err error) bool {
+	e2, ok := err.(MissingReplayGainError)
+	if !ok {
+		return false
+	}
+	return slices.Equal(e.Tracks, e2.Tracks)
+}
+
+var _ error = TooManyPicturesError{}
+
+type TooManyPicturesError struct {
+	Count int
+	Max   int
+}
+
+func (e TooManyPicturesError) Error() string {
+	return fmt.Sprintf("expected at most %d pictures, got %d", e.Max, e.Count)
+}
+
+func (e TooManyPicturesError) Is(err error) bool {
+	e2, ok := err.(TooManyPicturesError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = PictureTypeNotAllowedError{}
+
+type PictureTypeNotAllowedError struct {
+	Type flacpicture.PictureType
+}
+
+func (e PictureTypeNotAllowedError) Error() string {
+	return fmt.Sprintf("picture type %s isn't allowed", cover.TypeName(e.Type))
+}
+
+func (e PictureTypeNotAllowedError) Is(err error) bool {
+	e2, ok := err.(PictureTypeNotAllowedError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = InconsistentFrontCoverError{}
+
+type InconsistentFrontCoverError struct {
+	Tracks []string
+}
+
+func (e InconsistentFrontCoverError) Error() string {
+	return fmt.Sprintf("expected the same front cover on every track of the album, different on %s", join(e.Tracks))
+}
+
+func (e InconsistentFrontCoverError) Is(err error) bool {
+	e2, ok := err.(InconsistentFrontCoverError)
+	if !ok {
+		return false
+	}
+	return slices.Equal(e.Tracks, e2.Tracks)
+}
+
+var _ error = SidecarCoverMismatchError{}
+
+type SidecarCoverMismatchError struct {
+	Sidecar string
+	Tracks  []string
+}
+
+func (e SidecarCoverMismatchError) Error() string {
+	return fmt.Sprintf("expected the front cover to match sidecar cover %s, different on %s", e.Sidecar, join(e.Tracks))
+}
+
+func (e SidecarCoverMismatchError) Is(err error) bool {
+	e2, ok := err.(SidecarCoverMismatchError)
+	if !ok {
+		return false
+	}
+	return e.Sidecar == e2.Sidecar && slices.Equal(e.Tracks, e2.Tracks)
+}
+
+var _ error = CoverMismatchError{}
+
+type CoverMismatchError struct {
+	ReleaseID string
+	Distance  int
+}
+
+func (e CoverMismatchError) Error() string {
+	return fmt.Sprintf(
+		"expected the front cover to match the cover art of release %s, hashes differ by %d bits", e.ReleaseID, e.Distance,
+	)
+}
+
+func (e CoverMismatchError) Is(err error) bool {
+	e2, ok := err.(CoverMismatchError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = MixedAudioPropertyError{}
+
+// MixedAudioPropertyError is an album with tracks of different resolutions, with the tracks by value of the property.
+type MixedAudioPropertyError struct {
+	Property string
+	Tracks   map[int][]string
+}
+
+func (e MixedAudioPropertyError) Error() string {
+	values := make([]string, 0, len(e.Tracks))
+	for _, value := range slices.Sorted(maps.Keys(e.Tracks)) {
+		values = append(values, fmt.Sprintf("%d (%s)", value, join(slices.Clone(e.Tracks[value]))))
+	}
+	return fmt.Sprintf(
+		"expected every track of the album to have the same %s, got %s", e.Property, strings.Join(values, ", "),
+	)
+}
+
+func (e MixedAudioPropertyError) Is(err error) bool {
+	e2, ok := err.(MixedAudioPropertyError)
+	if !ok {
+		return false
+	}
+	return e.Property == e2.Property && maps.EqualFunc(e.Tracks, e2.Tracks, slices.Equal)
+}
+
+var _ error = UnknownTotalSamplesError{}
+
+type UnknownTotalSamplesError struct {
+	Track string
+}
+
+func (e UnknownTotalSamplesError) Error() string {
+	return fmt.Sprintf("expected the STREAMINFO block of track %s to have the total samples, got 0 (unknown)", e.Track)
+}
+
+func (e UnknownTotalSamplesError) Is(err error) bool {
+	e2, ok := err.(UnknownTotalSamplesError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = ShortTrackError{}
+
+type ShortTrackError struct {
+	Track    string
+	Duration time.Duration
+	Min      time.Duration
+}
+
+func (e ShortTrackError) Error() string {
+	return fmt.Sprintf("expected track %s to be at least %s long, got %s", e.Track, e.Min, e.Duration)
+}
+
+func (e ShortTrackError) Is(err error) bool {
+	e2, ok := err.(ShortTrackError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = ClippingError{}
+
+type ClippingError struct {
+	Track    string
+	Clipping float64
+	Max      float64
+}
+
+func (e ClippingError) Error() string {
+	return fmt.Sprintf("expected at most %g%% of track %s to be clipped, got %g%%", e.Max, e.Track, e.Clipping)
+}
+
+func (e ClippingError) Is(err error) bool {
+	e2, ok := err.(ClippingError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = DCOffsetError{}
+
+type DCOffsetError struct {
+	Track    string
+	Channel  int
+	DCOffset float64
+	Max      float64
+}
+
+func (e DCOffsetError) Error() string {
+	return fmt.Sprintf(
+		"expected channel %d of track %s to have a DC offset of at most %g%%, got %g%%",
+		e.Channel,
+		e.Track,
+		e.Max,
+		e.DCOffset,
+	)
+}
+
+func (e DCOffsetError) Is(err error) bool {
+	e2, ok := err.(DCOffsetError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = DuplicateAudioError{}
+
+// DuplicateAudioError is the same audio in tracks of different albums.
+type DuplicateAudioError struct {
+	MD5    string
+	Tracks []string
+	// Differences are the tags which aren't the same for every track
+	Differences []TagDifference
+}
+
+// TagDifference is the values of a tag for each of the tracks with the same audio, in the same order as the tracks.
+type TagDifference struct {
+	Tag    vorbis.Tag
+	Values []string
+}
+
+func (e DuplicateAudioError) Error() string {
+	msg := fmt.Sprintf(
+		"expected audio with MD5 %s to only be in one album, got %s", e.MD5, strings.Join(e.Tracks, ", "),
+	)
+	if len(e.Differences) == 0 {
+		return msg + " with the same tags"
+	}
+
+	differences := make([]string, 0, len(e.Differences))
+	for _, d := range e.Differences {
+		differences = append(differences, fmt.Sprintf("%s (%s)", d.Tag, strings.Join(d.Values, " | ")))
+	}
+	return msg + " with different " + strings.Join(differences, ", ")
+}
+
+func (e DuplicateAudioError) Is(err error) bool {
+	e2, ok := err.(DuplicateAudioError)
+	if !ok {
+		return false
+	}
+	return e.MD5 == e2.MD5 && slices.Equal(e.Tracks, e2.Tracks) &&
+		slices.EqualFunc(e.Differences, e2.Differences, func(a, b TagDifference) bool {
+			return a.Tag == b.Tag && slices.Equal(a.Values, b.Values)
+		})
+}
+
+var errMissingDiscTracks = errors.New("disc doesn't have every track")
+
+// ErrReportWithoutAnalysis is returned when a report is asked for without analyzing audio, as the report would be
+// empty.
+var ErrReportWithoutAnalysis = errors.New("a report can only be written when analyzing audio")
+
 func join(s []string) string {
 	if s == nil {
 		return "<nil>"
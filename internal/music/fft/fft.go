@@ -0,0 +1,89 @@
+// Package fft measures the spectrum of blocks of audio.
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// FFT is a radix-2 fast Fourier transform of a fixed size, with a window applied to the samples first.
+type FFT struct {
+	window []float64
+	// twiddles are the roots of unity used by each butterfly
+	twiddles []complex128
+	buf      []complex128
+	out      []float64
+}
+
+// New creates a transform the size of the window, which has to be a power of 2.
+func New(window []float64) *FFT {
+	size := len(window)
+	f := &FFT{
+		window:   window,
+		twiddles: make([]complex128, size/2),
+		buf:      make([]complex128, size),
+		out:      make([]float64, size/2),
+	}
+	for k := range f.twiddles {
+		f.twiddles[k] = cmplx.Exp(complex(0, -2*math.Pi*float64(k)/float64(size))) //nolint:mnd // full turn
+	}
+	return f
+}
+
+// Hann is a Hann window of the given size.
+func Hann(size int) []float64 {
+	window := make([]float64, size)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size-1)) //nolint:mnd // Hann window
+	}
+	return window
+}
+
+// Hamming is a Hamming window of the given size, multiplied by the scale.
+func Hamming(size int, scale float64) []float64 {
+	window := make([]float64, size)
+	for i := range window {
+		window[i] = scale * (0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(size-1))) //nolint:mnd // Hamming window
+	}
+	return window
+}
+
+// Power returns the power of each frequency bin of the samples, up to the Nyquist frequency. The returned slice is
+// reused by the next call.
+func (f *FFT) Power(samples []float64) []float64 {
+	n := len(f.buf)
+	for i, s := range samples {
+		f.buf[i] = complex(s*f.window[i], 0)
+	}
+
+	// Bit reversal permutation, so the butterflies can work in place
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			f.buf[i], f.buf[j] = f.buf[j], f.buf[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2 //nolint:mnd // butterflies pair up the halves
+		stride := n / size
+		for start := 0; start < n; start += size {
+			for k := range half {
+				v := f.buf[start+k+half] * f.twiddles[k*stride]
+				u := f.buf[start+k]
+				f.buf[start+k] = u + v
+				f.buf[start+k+half] = u - v
+			}
+		}
+	}
+
+	for i := range f.out {
+		re, im := real(f.buf[i]), imag(f.buf[i])
+		f.out[i] = re*re + im*im
+	}
+	return f.out
+}
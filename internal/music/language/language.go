@@ -0,0 +1,243 @@
+package language
+
+import (
+	"embed"
+	"fmt"
+	"maps"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Languages are ISO 639-3 codes, as used for the LANGUAGE tag.
+const (
+	Arabic     = "ara"
+	Chinese    = "zho"
+	Dutch      = "nld"
+	English    = "eng"
+	French     = "fra"
+	German     = "deu"
+	Greek      = "ell"
+	Hebrew     = "heb"
+	Hindi      = "hin"
+	Italian    = "ita"
+	Japanese   = "jpn"
+	Korean     = "kor"
+	Polish     = "pol"
+	Portuguese = "por"
+	Russian    = "rus"
+	Spanish    = "spa"
+	Swedish    = "swe"
+	Thai       = "tha"
+)
+
+// MinLetters is how many letters of Latin script text are needed to tell which language it is.
+const MinLetters = 20
+
+// Confidence is how sure the detection of the language of some text is.
+type Confidence int
+
+const (
+	// Undetected text is too short, or too close to more than one language, to tell which language it's in.
+	Undetected Confidence = iota
+	// Likely is the language the text is most likely to be in, but not by enough to rule out the others, such as
+	// lyrics which mix in lines of another language.
+	Likely
+	// Certain is the language of the script the text is written in, or the language the text is far more likely to
+	// be in than any other.
+	Certain
+)
+
+const (
+	// minMargin is how much more likely, as the mean log-likelihood per trigram, the text has to be in the most
+	// likely language than in the next most likely for the language to be detected.
+	minMargin = 0.15
+	// certainMargin is how much more likely the text has to be in the most likely language for it to be certain.
+	certainMargin = 0.3
+)
+
+// minScriptShare is the share of letters which have to be in a non-Latin script for the text to be in the language of
+// that script, as lyrics in other languages often have some English in them.
+const minScriptShare = 0.25
+
+// profiles are sample texts of each language written in the Latin script, named after the language.
+//
+//go:embed profiles/*.txt
+var profiles embed.FS
+
+// sampleTrigrams are the trigrams of the sample texts, only counted the first time they're needed.
+var sampleTrigrams = sync.OnceValues(loadTrigrams)
+
+// languageTrigrams are the trigrams of the sample text of each language, by the code of the language.
+type languageTrigrams struct {
+	langs map[string]trigrams
+	// vocabulary is how many different trigrams there are across all the sample texts.
+	vocabulary int
+}
+
+// loadTrigrams counts the trigrams of the sample text of each language written in the Latin script.
+func loadTrigrams() (languageTrigrams, error) {
+	langs := map[string]trigrams{}
+	vocabulary := map[string]struct{}{}
+	for _, lang := range latinLanguages() {
+		data, err := profiles.ReadFile(path.Join("profiles", lang+".txt"))
+		if err != nil {
+			return languageTrigrams{}, fmt.Errorf("failed to read sample text of %s: %w", lang, err)
+		}
+		langs[lang] = countTrigrams(string(data))
+		for g := range langs[lang].counts {
+			vocabulary[g] = struct{}{}
+		}
+	}
+	return languageTrigrams{langs: langs, vocabulary: len(vocabulary)}, nil
+}
+
+// latinLanguages returns the languages written in the Latin script, which are told apart by their sample texts.
+func latinLanguages() []string {
+	return []string{Dutch, English, French, German, Italian, Polish, Portuguese, Spanish, Swedish}
+}
+
+// scripts returns the languages which can be told apart by the script they're written in.
+func scripts() []script {
+	return []script{
+		// Japanese mixes kana with Chinese characters, so has to come before Chinese
+		{Japanese, []*unicode.RangeTable{unicode.Hiragana, unicode.Katakana}},
+		{Korean, []*unicode.RangeTable{unicode.Hangul}},
+		{Chinese, []*unicode.RangeTable{unicode.Han}},
+		{Russian, []*unicode.RangeTable{unicode.Cyrillic}},
+		{Greek, []*unicode.RangeTable{unicode.Greek}},
+		{Arabic, []*unicode.RangeTable{unicode.Arabic}},
+		{Hebrew, []*unicode.RangeTable{unicode.Hebrew}},
+		{Thai, []*unicode.RangeTable{unicode.Thai}},
+		{Hindi, []*unicode.RangeTable{unicode.Devanagari}},
+	}
+}
+
+type script struct {
+	language string
+	tables   []*unicode.RangeTable
+}
+
+// Codes returns the codes of all the languages which can be detected.
+func Codes() []string {
+	scripts := scripts()
+	codes := make([]string, 0, len(scripts)+len(latinLanguages()))
+	for _, s := range scripts {
+		codes = append(codes, s.language)
+	}
+	codes = append(codes, latinLanguages()...)
+	slices.Sort(codes)
+	return codes
+}
+
+// Detect works out the language of the text, first by the script it's written in and then, for the Latin script, by
+// how likely its letter trigrams are in each language. Text which is too short to tell isn't detected.
+func Detect(text string) (string, Confidence, error) {
+	scripts := scripts()
+	var letters, kana int
+	perScript := make([]int, len(scripts))
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for i, s := range scripts {
+			if unicode.In(r, s.tables...) {
+				perScript[i]++
+				break
+			}
+		}
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana) {
+			kana++
+		}
+	}
+	if letters == 0 {
+		return "", Undetected, nil
+	}
+
+	if kana > 0 {
+		// Chinese characters in Japanese text are still Japanese
+		perScript[slices.IndexFunc(scripts, func(s script) bool {
+			return s.language == Chinese
+		})] = 0
+	}
+
+	var nonLatin int
+	for _, n := range perScript {
+		nonLatin += n
+	}
+	if float64(nonLatin)/float64(letters) >= minScriptShare {
+		best := 0
+		for i, n := range perScript {
+			if n > perScript[best] {
+				best = i
+			}
+		}
+		return scripts[best].language, Certain, nil
+	}
+
+	if letters < MinLetters {
+		return "", Undetected, nil
+	}
+
+	return detectLatin(text)
+}
+
+// detectLatin picks the language with the sample text most likely to have the trigrams of the text, using add-one
+// smoothing for trigrams which aren't in the sample text. Text which is nearly as likely in another language isn't
+// detected.
+func detectLatin(text string) (string, Confidence, error) {
+	samples, err := sampleTrigrams()
+	if err != nil {
+		return "", Undetected, err
+	}
+
+	input := countTrigrams(text)
+	best, bestScore, nextScore := "", math.Inf(-1), math.Inf(-1)
+	for _, code := range slices.Sorted(maps.Keys(samples.langs)) {
+		t := samples.langs[code]
+		var score float64
+		for g, n := range input.counts {
+			probability := float64(t.counts[g]+1) / float64(t.total+samples.vocabulary)
+			score += float64(n) * math.Log(probability)
+		}
+		if score > bestScore {
+			best, bestScore, nextScore = code, score, bestScore
+		} else if score > nextScore {
+			nextScore = score
+		}
+	}
+
+	switch margin := (bestScore - nextScore) / float64(input.total); {
+	case margin >= certainMargin:
+		return best, Certain, nil
+	case margin >= minMargin:
+		return best, Likely, nil
+	default:
+		return "", Undetected, nil
+	}
+}
+
+type trigrams struct {
+	counts map[string]int
+	total  int
+}
+
+// countTrigrams counts the trigrams of letters in each word, padded with a space either side so the start & end of
+// words count too.
+func countTrigrams(text string) trigrams {
+	t := trigrams{counts: map[string]int{}}
+	for word := range strings.FieldsFuncSeq(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			t.counts[string(runes[i:i+3])]++
+			t.total++
+		}
+	}
+	return t
+}
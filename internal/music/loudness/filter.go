@@ -0,0 +1,118 @@
+package loudness
+
+import "math"
+
+// biquad is a second order IIR filter in direct form 1.
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+
+	x1, x2, y1, y2 float64
+}
+
+func (f *biquad) filter(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the two stages of the K-weighting filter - a high shelf modelling the acoustic effect of the
+// head, followed by a high pass - for the sample rate. BS.1770 only gives the coefficients at 48kHz, so these are
+// derived from the analog prototype of the filters.
+func kWeighting(sampleRate float64) (biquad, biquad) {
+	//nolint:mnd // filter parameters matching the BS.1770 coefficients at 48kHz
+	const (
+		shelfFrequency = 1681.974450955533
+		shelfGain      = 3.999843853973347
+		shelfQ         = 0.7071752369554196
+		shelfBandwidth = 0.4996667741545416
+		passFrequency  = 38.13547087602444
+		passQ          = 0.5003270373238773
+	)
+
+	k := math.Tan(math.Pi * shelfFrequency / sampleRate)
+	vh := math.Pow(10, shelfGain/20) //nolint:mnd // decibels to amplitude
+	vb := math.Pow(vh, shelfBandwidth)
+	a0 := 1 + k/shelfQ + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+
+	k = math.Tan(math.Pi * passFrequency / sampleRate)
+	a0 = 1 + k/passQ + k*k
+	pass := biquad{
+		b0: 1,
+		b1: -2, //nolint:mnd // high pass numerator
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/passQ + k*k) / a0,
+	}
+
+	return shelf, pass
+}
+
+const (
+	// oversampling is the factor the audio is oversampled by to find peaks between samples.
+	oversampling = 4
+	// interpolationTaps is the number of samples either side of the interpolated point used to interpolate it.
+	interpolationTaps = 6
+)
+
+type interpolator [oversampling - 1][2 * interpolationTaps]float64
+
+// newInterpolator calculates the coefficients for each of the points interpolated between two samples.
+func newInterpolator() *interpolator {
+	var in interpolator
+	for phase := range in {
+		for i := range in[phase] {
+			in[phase][i] = interpolationCoefficient(float64(i-interpolationTaps+1) - float64(phase+1)/oversampling)
+		}
+	}
+	return &in
+}
+
+// truePeak finds the peak of the audio signal, including peaks between samples, by oversampling the audio with a
+// windowed sinc interpolator.
+type truePeak struct {
+	interpolator *interpolator
+	history      [2 * interpolationTaps]float64
+	peak         float64
+}
+
+func (p *truePeak) add(s float64) {
+	copy(p.history[:], p.history[1:])
+	p.history[len(p.history)-1] = s
+
+	// The sample half way through the history is known exactly, along with the points between it & the next sample
+	p.peak = max(p.peak, math.Abs(p.history[interpolationTaps-1]))
+	for _, coefficients := range p.interpolator {
+		var v float64
+		for i, h := range p.history {
+			v += h * coefficients[i]
+		}
+		p.peak = max(p.peak, math.Abs(v))
+	}
+}
+
+func (p *truePeak) value() float64 {
+	// Account for the samples still in the history which haven't been the centre of the interpolation
+	peak := p.peak
+	for _, h := range p.history[interpolationTaps:] {
+		peak = max(peak, math.Abs(h))
+	}
+	return peak
+}
+
+// interpolationCoefficient is a Hann windowed sinc at the given distance, in samples, from the interpolated point.
+func interpolationCoefficient(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	window := 0.5 * (1 + math.Cos(math.Pi*x/interpolationTaps)) //nolint:mnd // Hann window
+	return window * math.Sin(math.Pi*x) / (math.Pi * x)
+}
@@ -0,0 +1,186 @@
+// Package loudness measures the loudness of audio, as defined by EBU R128 & ITU-R BS.1770.
+// https://tech.ebu.ch/docs/tech/tech3341.pdf
+// https://www.itu.int/rec/R-REC-BS.1770
+package loudness
+
+import (
+	"math"
+)
+
+const (
+	// absoluteGate is the loudness, in LUFS, below which blocks are ignored - and the loudness given to audio which is
+	// entirely below the gate, such as silence.
+	absoluteGate = -70
+	// relativeGate is how far, in LU, below the ungated loudness a block has to be to be ignored.
+	relativeGate = -10
+	// loudnessOffset compensates for the gain of the K-weighting filter at 997Hz.
+	loudnessOffset = -0.691
+	// replayGainReference is the loudness, in LUFS, that ReplayGain 2.0 normalises audio to.
+	replayGainReference = -18
+
+	// Gating blocks are 400ms long, overlapping by 75%, so are built from 100ms steps
+	stepsPerSecond = 10
+	stepsPerBlock  = 4
+
+	surroundWeight = 1.41
+	lfeChannel     = 3
+	surroundLayout = 6
+)
+
+// Measurement is the loudness and peak of some audio, which can be combined with the measurements of other audio -
+// such as the tracks of an album - to get the loudness of all the audio together.
+type Measurement struct {
+	// blocks are the channel weighted mean square of each gating block
+	blocks []float64
+	// Peak is the true peak as a ratio of full scale.
+	Peak float64
+}
+
+// Loudness returns the gated integrated loudness in LUFS.
+func (m Measurement) Loudness() float64 {
+	return integrated(m.blocks)
+}
+
+// ReplayGain returns the gain, in dB, needed to bring the audio to the ReplayGain 2.0 reference loudness.
+func (m Measurement) ReplayGain() float64 {
+	return replayGainReference - m.Loudness()
+}
+
+// Combine the measurements into a single measurement, as if all the audio was measured together.
+func Combine(measurements ...Measurement) Measurement {
+	var c Measurement
+	for _, m := range measurements {
+		c.blocks = append(c.blocks, m.blocks...)
+		c.Peak = max(c.Peak, m.Peak)
+	}
+	return c
+}
+
+// Meter measures audio a frame at a time.
+type Meter struct {
+	channels   []*channel
+	stepLength int
+	stepCount  int
+	// steps are the channel weighted sum of squares of the most recent steps, to build each gating block from
+	steps  []float64
+	blocks []float64
+}
+
+type channel struct {
+	weight float64
+	shelf  biquad
+	pass   biquad
+	peak   truePeak
+	sum    float64
+}
+
+func NewMeter(sampleRate, channels int) *Meter {
+	m := &Meter{
+		stepLength: sampleRate / stepsPerSecond,
+	}
+	shelf, pass := kWeighting(float64(sampleRate))
+	in := newInterpolator()
+	for i := range channels {
+		m.channels = append(m.channels, &channel{
+			weight: channelWeight(i, channels),
+			shelf:  shelf,
+			pass:   pass,
+			peak:   truePeak{interpolator: in},
+		})
+	}
+	return m
+}
+
+// Write adds samples to the measurement - one slice of samples per channel, with each sample a ratio of full scale.
+func (m *Meter) Write(samples [][]float64) {
+	for i := range samples[0] {
+		for c, ch := range m.channels {
+			s := samples[c][i]
+			ch.peak.add(s)
+			k := ch.pass.filter(ch.shelf.filter(s))
+			ch.sum += k * k
+		}
+
+		m.stepCount++
+		if m.stepCount == m.stepLength {
+			m.completeStep()
+		}
+	}
+}
+
+func (m *Meter) completeStep() {
+	var step float64
+	for _, ch := range m.channels {
+		step += ch.weight * ch.sum
+		ch.sum = 0
+	}
+	m.stepCount = 0
+
+	m.steps = append(m.steps, step)
+	if len(m.steps) < stepsPerBlock {
+		return
+	}
+	m.steps = m.steps[len(m.steps)-stepsPerBlock:]
+
+	var block float64
+	for _, s := range m.steps {
+		block += s
+	}
+	m.blocks = append(m.blocks, block/float64(stepsPerBlock*m.stepLength))
+}
+
+// Measurement returns the measurement of all the audio written so far.
+func (m *Meter) Measurement() Measurement {
+	var peak float64
+	for _, ch := range m.channels {
+		peak = max(peak, ch.peak.value())
+	}
+	return Measurement{
+		blocks: m.blocks,
+		Peak:   peak,
+	}
+}
+
+func integrated(blocks []float64) float64 {
+	gate := func(blocks []float64, threshold float64) ([]float64, float64) {
+		var gated []float64
+		var sum float64
+		for _, b := range blocks {
+			if blockLoudness(b) > threshold {
+				gated = append(gated, b)
+				sum += b
+			}
+		}
+		if len(gated) == 0 {
+			return nil, absoluteGate
+		}
+		return gated, blockLoudness(sum / float64(len(gated)))
+	}
+
+	gated, loudness := gate(blocks, absoluteGate)
+	if len(gated) == 0 {
+		return absoluteGate
+	}
+
+	_, loudness = gate(gated, loudness+relativeGate)
+	return loudness
+}
+
+func blockLoudness(meanSquare float64) float64 {
+	return loudnessOffset + 10*math.Log10(meanSquare) //nolint:mnd // power to decibels
+}
+
+// channelWeight weights surround channels, and ignores the LFE channel, of 5.1 audio.
+func channelWeight(channel, channels int) float64 {
+	if channels != surroundLayout {
+		return 1
+	}
+	switch {
+	case channel == lfeChannel:
+		return 0
+	case channel > lfeChannel:
+		return surroundWeight
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,113 @@
+package lrc
+
+import (
+	"fmt"
+	"time"
+)
+
+var _ error = InvalidTimestampError{}
+
+type InvalidTimestampError struct {
+	Line      int
+	Timestamp string
+}
+
+func (e InvalidTimestampError) Error() string {
+	return fmt.Sprintf("invalid lyrics timestamp %q on line %d", e.Timestamp, e.Line)
+}
+
+func (e InvalidTimestampError) Is(err error) bool {
+	e2, ok := err.(InvalidTimestampError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = UntimedLineError{}
+
+type UntimedLineError struct {
+	Line int
+	Text string
+}
+
+func (e UntimedLineError) Error() string {
+	return fmt.Sprintf("expected synced lyrics line %d to have a timestamp, got %q", e.Line, e.Text)
+}
+
+func (e UntimedLineError) Is(err error) bool {
+	e2, ok := err.(UntimedLineError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = TimestampOrderError{}
+
+type TimestampOrderError struct {
+	Line      int
+	Timestamp string
+	Previous  string
+}
+
+func (e TimestampOrderError) Error() string {
+	return fmt.Sprintf(
+		"lyrics timestamp %q on line %d is before the previous timestamp %q",
+		e.Timestamp,
+		e.Line,
+		e.Previous,
+	)
+}
+
+func (e TimestampOrderError) Is(err error) bool {
+	e2, ok := err.(TimestampOrderError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = TimestampBeyondDurationError{}
+
+type TimestampBeyondDurationError struct {
+	Line      int
+	Timestamp string
+	Duration  time.Duration
+}
+
+func (e TimestampBeyondDurationError) Error() string {
+	return fmt.Sprintf(
+		"lyrics timestamp %q on line %d is after the end of the track at %s",
+		e.Timestamp,
+		e.Line,
+		e.Duration,
+	)
+}
+
+func (e TimestampBeyondDurationError) Is(err error) bool {
+	e2, ok := err.(TimestampBeyondDurationError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = LengthHeaderError{}
+
+type LengthHeaderError struct {
+	Length   string
+	Duration time.Duration
+}
+
+func (e LengthHeaderError) Error() string {
+	return fmt.Sprintf("lyrics length header %q doesn't match the track duration of %s", e.Length, e.Duration)
+}
+
+func (e LengthHeaderError) Is(err error) bool {
+	e2, ok := err.(LengthHeaderError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
@@ -0,0 +1,266 @@
+package lrc
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ArtistHeader = "ar"
+	AlbumHeader  = "al"
+	TitleHeader  = "ti"
+	LengthHeader = "length"
+)
+
+// LengthTolerance is how far the [length:] header can be from the duration of the track.
+const LengthTolerance = time.Second
+
+var (
+	// tagPattern is a tag at the start of a line, such as a timestamp or a header.
+	tagPattern = regexp.MustCompile(`^\[([^]]*)]`)
+	// timestampPattern is minutes & seconds, with optional fractions of a second - after either a '.' or a ':'.
+	timestampPattern = regexp.MustCompile(`^(\d+):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+	// headerPattern is an ID tag, such as [ar:Artist].
+	headerPattern = regexp.MustCompile(`^([a-z#]+):(.*)$`)
+)
+
+// Lyrics are parsed LRC lyrics.
+type Lyrics struct {
+	Headers []Header
+	Lines   []Line
+}
+
+// Header is an ID tag of the lyrics, such as the artist or album.
+type Header struct {
+	Key   string
+	Value string
+}
+
+// Line is a single line of lyrics, along with when it's sung.
+type Line struct {
+	// Number is the line number in the original text, starting from 1
+	Number int
+	// Timestamp is the timestamp as it appeared in the original text
+	Timestamp string
+	Time      time.Duration
+	Text      string
+}
+
+// IsSynced is whether the text has at least one line starting with a timestamp.
+func IsSynced(text string) bool {
+	for line := range strings.Lines(text) {
+		if m := tagPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if _, ok := parseTimestamp(m[1]); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Text returns just the words of the lyrics, without any timestamps or headers.
+func Text(text string) string {
+	l, _ := Parse(text)
+	lines := make([]string, 0, len(l.Lines))
+	for _, line := range l.Lines {
+		lines = append(lines, line.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Parse parses LRC lyrics, returning all the problems with the syntax along with as much of the lyrics as could be
+// understood. A line can have several timestamps, each of which becomes a separate line.
+func Parse(text string) (*Lyrics, error) {
+	var l Lyrics
+	var errs []error
+	for i, line := range slices.Collect(strings.Lines(text)) {
+		number := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var timestamps []string
+		var header bool
+		for {
+			m := tagPattern.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+
+			tag := strings.TrimSpace(m[1])
+			if h := headerPattern.FindStringSubmatch(tag); h != nil && len(timestamps) == 0 {
+				l.Headers = append(l.Headers, Header{Key: h[1], Value: strings.TrimSpace(h[2])})
+				header = true
+				break
+			}
+			if !looksLikeTimestamp(tag) {
+				// Text such as [Chorus]
+				break
+			}
+
+			timestamps = append(timestamps, m[1])
+			line = strings.TrimSpace(line[len(m[0]):])
+		}
+		if header {
+			continue
+		}
+
+		if len(timestamps) == 0 {
+			errs = append(errs, UntimedLineError{Line: number, Text: line})
+			continue
+		}
+
+		for _, timestamp := range timestamps {
+			at, ok := parseTimestamp(timestamp)
+			if !ok {
+				errs = append(errs, InvalidTimestampError{Line: number, Timestamp: timestamp})
+				continue
+			}
+			l.Lines = append(l.Lines, Line{Number: number, Timestamp: timestamp, Time: at, Text: line})
+		}
+	}
+
+	return &l, errors.Join(errs...)
+}
+
+// Synced is whether there are any timed lines.
+func (l *Lyrics) Synced() bool {
+	return len(l.Lines) > 0
+}
+
+// Header returns the value of the header with the key, such as ArtistHeader.
+func (l *Lyrics) Header(key string) (string, bool) {
+	for _, h := range l.Headers {
+		if h.Key == key {
+			return h.Value, true
+		}
+	}
+	return "", false
+}
+
+// Validate checks the lines are in order, and against the duration of the track if it's known. A line with several
+// timestamps, such as a repeated chorus, is in order if its first timestamp is.
+func (l *Lyrics) Validate(duration time.Duration) error {
+	var errs []error
+	var previous *Line
+	for i, line := range l.Lines {
+		if previous != nil && line.Number == previous.Number {
+			continue
+		}
+		if previous != nil && line.Time < previous.Time {
+			errs = append(errs, TimestampOrderError{
+				Line:      line.Number,
+				Timestamp: line.Timestamp,
+				Previous:  previous.Timestamp,
+			})
+		}
+		previous = &l.Lines[i]
+	}
+
+	if duration <= 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, line := range l.Lines {
+		if line.Time > duration {
+			errs = append(errs, TimestampBeyondDurationError{
+				Line:      line.Number,
+				Timestamp: line.Timestamp,
+				Duration:  duration,
+			})
+		}
+	}
+
+	if value, ok := l.Header(LengthHeader); ok {
+		length, ok := parseTimestamp(value)
+		if !ok || (length-duration).Abs() > LengthTolerance {
+			errs = append(errs, LengthHeaderError{Length: value, Duration: duration})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Fix sorts the lines by time, and sets the [length:] header to the duration of the track if it's known and there
+// already is one.
+func (l *Lyrics) Fix(duration time.Duration) {
+	slices.SortStableFunc(l.Lines, func(a, b Line) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+
+	if duration <= 0 {
+		return
+	}
+	for i, h := range l.Headers {
+		if h.Key == LengthHeader {
+			seconds := int(duration.Round(time.Second).Seconds())
+			l.Headers[i].Value = fmt.Sprintf("%02d:%02d", seconds/60, seconds%60) //nolint:mnd // seconds in a minute
+		}
+	}
+}
+
+// String formats the lyrics as LRC, with the headers first and then a line per timestamp.
+func (l *Lyrics) String() string {
+	var b strings.Builder
+	for _, h := range l.Headers {
+		fmt.Fprintf(&b, "[%s:%s]\n", h.Key, h.Value)
+	}
+	for _, line := range l.Lines {
+		fmt.Fprintf(&b, "[%s]%s\n", formatTimestamp(line.Time), line.Text)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// looksLikeTimestamp is whether the tag is meant to be a timestamp, even if it's not a valid one.
+func looksLikeTimestamp(tag string) bool {
+	return tag != "" && tag[0] >= '0' && tag[0] <= '9' && strings.Contains(tag, ":")
+}
+
+func parseTimestamp(s string) (time.Duration, bool) {
+	m := timestampPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	}
+
+	minutes, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(m[2])
+	if err != nil || seconds >= 60 { //nolint:mnd // seconds in a minute
+		return 0, false
+	}
+
+	at := time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	if m[3] != "" {
+		fraction, err := strconv.Atoi(m[3])
+		if err != nil {
+			return 0, false
+		}
+		// Scale hundredths or thousandths to the right unit
+		unit := time.Second
+		for range len(m[3]) {
+			unit /= 10
+		}
+		at += time.Duration(fraction) * unit
+	}
+
+	return at, true
+}
+
+func formatTimestamp(d time.Duration) string {
+	centiseconds := d / (10 * time.Millisecond) //nolint:mnd // hundredths of a second
+	return fmt.Sprintf(
+		"%02d:%02d.%02d",
+		centiseconds/(60*100), //nolint:mnd // centiseconds in a minute
+		centiseconds/100%60,   //nolint:mnd // seconds in a minute
+		centiseconds%100,      //nolint:mnd // centiseconds in a second
+	)
+}
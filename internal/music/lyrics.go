@@ -0,0 +1,161 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/logging"
+	"github.com/wjam/flac-check/internal/music/lrc"
+	"github.com/wjam/flac-check/internal/music/track"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+	"github.com/wjam/flac-check/internal/walk"
+)
+
+type LyricsOptions struct {
+	LanguageOptions
+
+	Write bool
+}
+
+// LyricsExport writes the lyrics of every track to a .lrc file next to it, for players which don't read embedded
+// lyrics.
+type LyricsExport struct {
+	path string
+	opts LyricsOptions
+}
+
+func NewLyricsExport(path string, opts LyricsOptions) *LyricsExport {
+	return &LyricsExport{
+		path: path,
+		opts: opts,
+	}
+}
+
+func (l *LyricsExport) Run(ctx context.Context) error {
+	return forEachTrack(ctx, l.path, l.exportTrack)
+}
+
+// exportTrack writes the synced lyrics of the track, or the unsynced lyrics if there aren't any, to the .lrc file
+// unless it already has the same lyrics.
+func (l *LyricsExport) exportTrack(ctx context.Context, t *track.Track) error {
+	lyrics, ok := singleTag(t, vorbis.LyricsTag)
+	if !ok {
+		lyrics, ok = singleTag(t, vorbis.UnsyncedLyricsTag)
+	}
+	if !ok {
+		return nil
+	}
+
+	path := lyricsFileName(t)
+	existing, err := readLyricsFile(path)
+	if err != nil {
+		return err
+	}
+	if existing == strings.TrimSpace(lyrics) {
+		return nil
+	}
+
+	if !l.opts.Write {
+		logging.FromContext(ctx).WarnContext(ctx, "Updated lyrics file", slog.String("file", path))
+		return nil
+	}
+
+	logging.FromContext(ctx).WarnContext(ctx, "Saving lyrics file", slog.String("file", path))
+	//nolint:gosec // lyrics files are meant to be readable by everyone, like the tracks themselves
+	return os.WriteFile(path, []byte(strings.TrimSpace(lyrics)+"\n"), 0o644)
+}
+
+// LyricsImport embeds the lyrics from the .lrc file next to each track without lyrics, as synced lyrics if they have
+// timestamps or unsynced lyrics otherwise.
+type LyricsImport struct {
+	path string
+	opts LyricsOptions
+}
+
+func NewLyricsImport(path string, opts LyricsOptions) *LyricsImport {
+	return &LyricsImport{
+		path: path,
+		opts: opts,
+	}
+}
+
+func (l *LyricsImport) Run(ctx context.Context) error {
+	return forEachTrack(ctx, l.path, l.importTrack)
+}
+
+func (l *LyricsImport) importTrack(ctx context.Context, t *track.Track) error {
+	if t.HasLyrics() {
+		return nil
+	}
+
+	lyrics, err := readLyricsFile(lyricsFileName(t))
+	if err != nil || lyrics == "" {
+		return err
+	}
+
+	if lrc.IsSynced(lyrics) {
+		err = t.SetSyncedLyrics(ctx, lyrics, l.opts.Allowed(t), LocalLyricsProvider)
+	} else {
+		err = t.SetUnsyncedLyrics(ctx, lyrics, l.opts.Allowed(t), LocalLyricsProvider)
+	}
+	if err != nil {
+		return err
+	}
+
+	return t.Save(ctx, l.opts.Write)
+}
+
+// forEachTrack calls the function for every FLAC file under the path.
+func forEachTrack(ctx context.Context, path string, fn func(context.Context, *track.Track) error) error {
+	var errs []error
+	for e, err := range walk.DirIter(path) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !e.Entry.IsDir() {
+			continue
+		}
+
+		entries, err := os.ReadDir(e.Path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		ctx := logging.WithAttrs(ctx, slog.String("path", e.Path))
+		tracks, err := readAllFlacTracks(ctx, e.Path, filesOnly(entries))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("album %s: %w", e.Path, err))
+			continue
+		}
+
+		for _, t := range tracks {
+			ctx := logging.WithAttrs(ctx, slog.String("track", t.String()))
+			if err := fn(ctx, t); err != nil {
+				errs = append(errs, fmt.Errorf("track %s: %w", t.Path(), err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// lyricsFileName is the .lrc file next to the track with the same name.
+func lyricsFileName(t *track.Track) string {
+	return strings.TrimSuffix(t.Path(), ".flac") + ".lrc"
+}
+
+// singleTag returns the value of the tag, if it has a single non-blank value.
+func singleTag(t *track.Track, tag vorbis.Tag) (string, bool) {
+	v, ok := t.TagOk(tag)
+	if !ok || len(v) != 1 || strings.TrimSpace(v[0]) == "" {
+		return "", false
+	}
+	return v[0], true
+}
@@ -0,0 +1,200 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wjam/flac-check/internal/genius"
+	"github.com/wjam/flac-check/internal/lrclib"
+)
+
+// LyricsProvider is somewhere to find the lyrics of tracks without any.
+type LyricsProvider interface {
+	// Name identifies the provider in the tag recording where lyrics came from.
+	Name() string
+	// Lyrics returns the lyrics of the track, or false if the provider doesn't have any.
+	Lyrics(ctx context.Context, track LyricsQuery) (Lyrics, bool, error)
+}
+
+// LyricsQuery describes the track to find the lyrics of.
+type LyricsQuery struct {
+	Title  string
+	Artist string
+	Album  string
+	// Path is the path of the FLAC file of the track
+	Path string
+	// Duration is the length of the track, or zero if it isn't known
+	Duration time.Duration
+}
+
+// Lyrics found by a lyrics provider, with synced lyrics in the LRC format.
+type Lyrics struct {
+	Synced       string
+	Plain        string
+	Instrumental bool
+}
+
+const (
+	LRCLibLyricsProvider       = "lrclib"
+	LRCLibSearchLyricsProvider = "lrclib-search"
+	GeniusLyricsProvider       = "genius"
+	LocalLyricsProvider        = "local"
+)
+
+// DefaultLyricsDurationTolerance is how far the duration of lyrics found by searching can be from the track by default.
+const DefaultLyricsDurationTolerance = 3 * time.Second
+
+// LyricsProviderNames returns the names of every lyrics provider, in the default order they're tried.
+func LyricsProviderNames() []string {
+	return []string{LRCLibLyricsProvider, LRCLibSearchLyricsProvider, GeniusLyricsProvider, LocalLyricsProvider}
+}
+
+var _ LyricsProvider = lrcLibLyricsProvider{}
+
+// lrcLibLyricsProvider is the lyrics of the track with exactly the same title, artist & album on LRCLIB.
+type lrcLibLyricsProvider struct {
+	lyrics *lrclib.Client
+}
+
+func (lrcLibLyricsProvider) Name() string {
+	return LRCLibLyricsProvider
+}
+
+func (p lrcLibLyricsProvider) Lyrics(ctx context.Context, track LyricsQuery) (Lyrics, bool, error) {
+	lyrics, err := p.lyrics.FindLyricsForTrack(ctx, track.Title, track.Artist, track.Album, track.Duration)
+	return fromLRCLib(lyrics, err)
+}
+
+var _ LyricsProvider = lrcLibSearchLyricsProvider{}
+
+// lrcLibSearchLyricsProvider is the lyrics of the track with a similar title, artist & duration, on any album, on
+// LRCLIB.
+type lrcLibSearchLyricsProvider struct {
+	lyrics    *lrclib.Client
+	tolerance time.Duration
+}
+
+func (lrcLibSearchLyricsProvider) Name() string {
+	return LRCLibSearchLyricsProvider
+}
+
+func (p lrcLibSearchLyricsProvider) Lyrics(ctx context.Context, track LyricsQuery) (Lyrics, bool, error) {
+	lyrics, err := p.lyrics.SearchLyricsForTrack(ctx, track.Title, track.Artist, track.Duration, p.tolerance)
+	return fromLRCLib(lyrics, err)
+}
+
+func fromLRCLib(lyrics *lrclib.Lyrics, err error) (Lyrics, bool, error) {
+	if errors.Is(err, lrclib.ErrNoLyricsFound) {
+		return Lyrics{}, false, nil
+	}
+	if err != nil {
+		return Lyrics{}, false, err
+	}
+
+	if !lyrics.Instrumental && lyrics.SyncedLyrics == "" && lyrics.PlainLyrics == "" {
+		return Lyrics{}, false, errors.New("lyrics was empty")
+	}
+
+	return Lyrics{
+		Synced:       lyrics.SyncedLyrics,
+		Plain:        lyrics.PlainLyrics,
+		Instrumental: lyrics.Instrumental,
+	}, true, nil
+}
+
+var _ LyricsProvider = geniusLyricsProvider{}
+
+// geniusLyricsProvider is the plain lyrics of the song with the same title & artist on Genius, if there's an API
+// token to search with.
+type geniusLyricsProvider struct {
+	genius *genius.Client
+	token  string
+}
+
+func (geniusLyricsProvider) Name() string {
+	return GeniusLyricsProvider
+}
+
+func (p geniusLyricsProvider) Lyrics(ctx context.Context, track LyricsQuery) (Lyrics, bool, error) {
+	if p.token == "" {
+		return Lyrics{}, false, nil
+	}
+
+	lyrics, err := p.genius.FindLyricsForTrack(ctx, track.Title, track.Artist)
+	if errors.Is(err, genius.ErrNoLyricsFound) {
+		return Lyrics{}, false, nil
+	}
+	if err != nil {
+		return Lyrics{}, false, err
+	}
+
+	return Lyrics{Plain: lyrics}, true, nil
+}
+
+var _ LyricsProvider = localLyricsProvider{}
+
+// localLyricsProvider is the lyrics from a sidecar file next to the track with the same name - synced lyrics from a
+// .lrc file or plain lyrics from a .txt file.
+type localLyricsProvider struct{}
+
+func (localLyricsProvider) Name() string {
+	return LocalLyricsProvider
+}
+
+func (localLyricsProvider) Lyrics(_ context.Context, track LyricsQuery) (Lyrics, bool, error) {
+	base := strings.TrimSuffix(track.Path, ".flac")
+
+	synced, err := readLyricsFile(base + ".lrc")
+	if err != nil {
+		return Lyrics{}, false, err
+	}
+	plain, err := readLyricsFile(base + ".txt")
+	if err != nil {
+		return Lyrics{}, false, err
+	}
+
+	if synced == "" && plain == "" {
+		return Lyrics{}, false, nil
+	}
+
+	return Lyrics{Synced: synced, Plain: plain}, true, nil
+}
+
+func readLyricsFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// lyricsProviders creates the lyrics providers with the given names, in the same order.
+func (s *Scan) lyricsProviders(names []string) ([]LyricsProvider, error) {
+	var providers []LyricsProvider
+	for _, name := range names {
+		switch name {
+		case LRCLibLyricsProvider:
+			providers = append(providers, lrcLibLyricsProvider{lyrics: s.lyrics})
+		case LRCLibSearchLyricsProvider:
+			providers = append(providers, lrcLibSearchLyricsProvider{
+				lyrics:    s.lyrics,
+				tolerance: s.opts.LyricsDurationTolerance,
+			})
+		case GeniusLyricsProvider:
+			providers = append(providers, geniusLyricsProvider{genius: s.genius, token: s.opts.GeniusToken})
+		case LocalLyricsProvider:
+			providers = append(providers, localLyricsProvider{})
+		default:
+			return nil, fmt.Errorf("unknown lyrics provider %q", name)
+		}
+	}
+	return providers, nil
+}
@@ -2,43 +2,179 @@ package music
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
+	"github.com/wjam/flac-check/internal/acoustid"
 	"github.com/wjam/flac-check/internal/coverart"
+	"github.com/wjam/flac-check/internal/genius"
+	"github.com/wjam/flac-check/internal/itunes"
 	"github.com/wjam/flac-check/internal/lrclib"
+	"github.com/wjam/flac-check/internal/music/cover"
 	"github.com/wjam/flac-check/internal/music/track"
+	"github.com/wjam/flac-check/internal/music/vorbis"
 	"github.com/wjam/flac-check/internal/musicbrainz"
 	"github.com/wjam/flac-check/internal/walk"
 	"github.com/wjam/flac-check/internal/wikidata"
 	"github.com/wjam/flac-check/internal/wikipedia"
 
 	"github.com/carlmjohnson/requests"
+	"github.com/go-flac/flacpicture/v2"
 	"github.com/sourcegraph/conc/pool"
 )
 
-type ScanOptions struct {
-	Write                bool
+// DefaultSilenceThreshold is the peak level, in dBFS, at or below which a track is silent - just above the level of
+// the least significant bit of 16 bit audio, so dither still counts as silence.
+const DefaultSilenceThreshold = -90
+
+// What to do with suspicious lyrics already in a track.
+const (
+	SuspiciousLyricsIgnore = "ignore"
+	SuspiciousLyricsReport = "report"
+	SuspiciousLyricsRemove = "remove"
+)
+
+// LanguageOptions are the languages lyrics are allowed to be in.
+type LanguageOptions struct {
+	// Languages are the languages lyrics can be in, unless the artist or album has their own
+	Languages []string
+	// ArtistLanguages are the languages lyrics can be in for each artist
+	ArtistLanguages map[string][]string
+	// AlbumLanguages are the languages lyrics can be in for each album, taking precedence over the artist
+	AlbumLanguages map[string][]string
+	// InternationalArtists are the artists whose lyrics can be in any language
+	//
+	// Deprecated: use ArtistLanguages.
 	InternationalArtists []string
-	SilenceAlbumTracks   map[string][]int
-	Parallelism          uint16
+}
 
-	FetchLyrics        bool
+// Allowed returns the languages the lyrics of the track can be in, with none meaning any language.
+func (o LanguageOptions) Allowed(t *track.Track) []string {
+	for _, album := range t.Tag(vorbis.AlbumTag) {
+		if languages, ok := o.AlbumLanguages[album]; ok {
+			return languages
+		}
+	}
+	for _, artist := range t.Tag(vorbis.ArtistTag) {
+		if slices.Contains(o.InternationalArtists, artist) {
+			return nil
+		}
+		if languages, ok := o.ArtistLanguages[artist]; ok {
+			return languages
+		}
+	}
+	return o.Languages
+}
+
+type ScanOptions struct {
+	LanguageOptions
+
+	Write              bool
+	SilenceAlbumTracks map[string][]int
+	Parallelism        uint16
+	ComputeDiscID      bool
+	WriteDiscID        bool
+	CopyCueSheetTags   bool
+	ReplayGain         bool
+	DetectSilence      bool
+	SilenceThreshold   float64
+	AnalyzeAudio       bool
+	ResolutionTag      bool
+	CoverPolicy        cover.Policy
+	FixCover           bool
+	MaxPictures        int
+	// MinTrackDuration is the length below which a track is abnormally short
+	MinTrackDuration time.Duration
+	// MaxClipping is the percentage of samples which can be clipped when analyzing audio
+	MaxClipping float64
+	// MaxDCOffset is the DC offset, as a percentage of full scale, any channel can have when analyzing audio
+	MaxDCOffset float64
+	// Report is the file to write a JSON report of the audio analysis of each album to
+	Report string
+	// ValidateLayout is whether to check the order & number of the metadata blocks of each file, normalising the
+	// layout with Write
+	ValidateLayout bool
+	// AllowedApplications are the IDs of the applications whose APPLICATION blocks are allowed when validating the
+	// layout
+	AllowedApplications []string
+	// ValidateSeekTable is whether to check the SEEKTABLE of each file, generating a new one with Write if it's
+	// missing or invalid
+	ValidateSeekTable bool
+	// SeekTableInterval is the time between the seek points of a generated SEEKTABLE
+	SeekTableInterval time.Duration
+	// AllowedPictureTypes are the types of picture allowed, with all types allowed if empty
+	AllowedPictureTypes []flacpicture.PictureType
+	// FetchPictureTypes are the types of picture, other than the front cover, to fetch from the Cover Art Archive
+	FetchPictureTypes []flacpicture.PictureType
+	// SidecarCover checks the cover.jpg or folder.jpg in the album directory, embedding it in tracks without a
+	// picture, or creates it from the front cover if missing
+	SidecarCover bool
+	// CheckReleaseCover compares the front cover against the Cover Art Archive front cover of the release
+	CheckReleaseCover bool
+	// CoverHash is the perceptual hash used to compare cover art
+	CoverHash cover.Algorithm
+	// CoverHashDistance is the largest number of bits the hashes of two images can differ by to be the same cover art
+	CoverHashDistance int
+
+	// CoverSources are the names of the cover sources to find missing front covers with, in the order to try them
+	CoverSources []string
+	// CoverDir is the directory of manually sourced covers for the local cover source
+	CoverDir string
+
+	FetchLyrics bool
+	// LyricsProviders are the names of the lyrics providers to find missing lyrics with, in the order to try them
+	LyricsProviders []string
+	// SuspiciousLyrics is what to do with embedded lyrics which are probably for a different version of the track or
+	// just a placeholder - one of SuspiciousLyricsIgnore, SuspiciousLyricsReport or SuspiciousLyricsRemove
+	SuspiciousLyrics string
+	// ValidateLyrics is whether to check the timestamps of synced lyrics & that lyrics are in the right tag
+	ValidateLyrics bool
+	// LyricsDurationTolerance is how far the duration of lyrics found by searching can be from the track
+	LyricsDurationTolerance time.Duration
+	// GeniusToken is the access token of a Genius API client, without which Genius isn't used
+	GeniusToken string
+	// AcoustIDKey is the API key of an AcoustID application, without which tracks aren't fingerprinted
+	AcoustIDKey string
+
+	AcoustidBaseURL    string
 	CoverartBaseURL    string
+	GeniusBaseURL      string
+	ItunesBaseURL      string
 	LrclibBaseURL      string
 	MusicbrainzBaseURL string
 	WikipediaBaseURL   string
 	WikidataBaseURL    string
 }
 
+func (s ScanOptions) acoustIDClient() *acoustid.Client {
+	return acoustid.New(s.AcoustIDKey, func(rb *requests.Builder) {
+		rb.BaseURL(s.AcoustidBaseURL)
+	})
+}
+
 func (s ScanOptions) artClient() *coverart.Client {
 	return coverart.New(func(rb *requests.Builder) {
 		rb.BaseURL(s.CoverartBaseURL)
 	})
 }
 
+func (s ScanOptions) geniusClient() *genius.Client {
+	return genius.New(s.GeniusToken, func(rb *requests.Builder) {
+		rb.BaseURL(s.GeniusBaseURL)
+	})
+}
+
+func (s ScanOptions) iTunesClient() *itunes.Client {
+	return itunes.New(func(rb *requests.Builder) {
+		rb.BaseURL(s.ItunesBaseURL)
+	})
+}
+
 func (s ScanOptions) lrcLibClient() *lrclib.Client {
 	return lrclib.New(func(rb *requests.Builder) {
 		rb.BaseURL(s.LrclibBaseURL)
@@ -52,9 +188,7 @@ func (s ScanOptions) musicBrainzClient() *musicbrainz.Client {
 }
 
 func (s ScanOptions) wikipediaClient(brainz *musicbrainz.Client, data *wikidata.Client) *wikipedia.Client {
-	return wikipedia.New(brainz, data, func(rb *requests.Builder) {
-		rb.BaseURL(s.WikipediaBaseURL)
-	})
+	return wikipedia.New(brainz, data, s.WikipediaBaseURL)
 }
 func (s ScanOptions) wikidataClient() *wikidata.Client {
 	return wikidata.New(func(rb *requests.Builder) {
@@ -65,11 +199,19 @@ func (s ScanOptions) wikidataClient() *wikidata.Client {
 type Scan struct {
 	path   string
 	opts   ScanOptions
+	acoust *acoustid.Client
 	art    *coverart.Client
+	genius *genius.Client
+	itunes *itunes.Client
 	lyrics *lrclib.Client
 	music  *musicbrainz.Client
 	wiki   *wikipedia.Client
 	data   *wikidata.Client
+	hashes *cover.HashCache
+	covers []CoverSource
+	report *report
+
+	lyricProviders []LyricsProvider
 }
 
 func NewScan(path string, opts ScanOptions) *Scan {
@@ -78,15 +220,36 @@ func NewScan(path string, opts ScanOptions) *Scan {
 	return &Scan{
 		path:   path,
 		opts:   opts,
+		acoust: opts.acoustIDClient(),
 		art:    opts.artClient(),
+		genius: opts.geniusClient(),
+		itunes: opts.iTunesClient(),
 		lyrics: opts.lrcLibClient(),
 		music:  brainz,
 		wiki:   opts.wikipediaClient(brainz, data),
 		data:   data,
+		hashes: &cover.HashCache{},
+		report: &report{},
 	}
 }
 
 func (s *Scan) Run(ctx context.Context) error {
+	if s.opts.Report != "" && !s.opts.AnalyzeAudio {
+		return ErrReportWithoutAnalysis
+	}
+
+	covers, err := s.coverSources(s.opts.CoverSources)
+	if err != nil {
+		return err
+	}
+	s.covers = covers
+
+	providers, err := s.lyricsProviders(s.opts.LyricsProviders)
+	if err != nil {
+		return err
+	}
+	s.lyricProviders = providers
+
 	group := pool.New().WithErrors().WithMaxGoroutines(int(s.opts.Parallelism)).WithContext(ctx)
 	for e, err := range walk.DirIter(s.path) {
 		if err != nil {
@@ -124,7 +287,13 @@ func (s *Scan) Run(ctx context.Context) error {
 		})
 	}
 
-	return group.Wait()
+	err = group.Wait()
+
+	if s.opts.Report != "" {
+		err = errors.Join(err, s.report.write(s.opts.Report))
+	}
+
+	return err
 }
 
 func filesOnly(entries []fs.DirEntry) []fs.DirEntry {
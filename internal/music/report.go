@@ -0,0 +1,118 @@
+package music
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// report collects the audio analysis of each album, from albums handled in parallel, for the JSON report.
+type report struct {
+	mu     sync.Mutex
+	albums []albumReport
+}
+
+type jsonReport struct {
+	Albums []albumReport `json:"albums"`
+}
+
+type albumReport struct {
+	Path           string `json:"path"`
+	Samples        int64  `json:"samples"`
+	ClippedSamples int64  `json:"clippedSamples"`
+	ClippedRuns    int    `json:"clippedRuns"`
+	// Clipping is the percentage of samples which are clipped
+	Clipping float64 `json:"clipping"`
+	// ClippedTracks is the number of tracks with any clipping
+	ClippedTracks int `json:"clippedTracks"`
+	// MaxDCOffset is the largest DC offset of any channel of any track, as a percentage of full scale
+	MaxDCOffset float64 `json:"maxDCOffset"`
+	// Resolutions are the distinct estimated resolutions of the tracks
+	Resolutions []string      `json:"resolutions"`
+	Tracks      []trackReport `json:"tracks"`
+}
+
+type trackReport struct {
+	Track          string  `json:"track"`
+	Resolution     string  `json:"resolution,omitempty"`
+	Samples        int64   `json:"samples"`
+	ClippedSamples int64   `json:"clippedSamples"`
+	ClippedRuns    int     `json:"clippedRuns"`
+	Clipping       float64 `json:"clipping"`
+	// DCOffset is the DC offset of each channel, as a percentage of full scale
+	DCOffset []float64 `json:"dcOffset"`
+}
+
+// add the statistics of the tracks of the album which have had their audio analysed.
+func (r *report) add(path string, a album) {
+	ar := albumReport{Path: path, Resolutions: []string{}}
+	for _, t := range a {
+		analysis := t.AudioAnalysis()
+		if analysis == nil {
+			continue
+		}
+
+		tr := trackReport{Track: t.String(), DCOffset: []float64{}}
+		if analysis.Resolution != nil {
+			tr.Resolution = analysis.Resolution.String()
+			if !slices.Contains(ar.Resolutions, tr.Resolution) {
+				ar.Resolutions = append(ar.Resolutions, tr.Resolution)
+			}
+		}
+		for _, c := range analysis.Channels {
+			tr.Samples += c.Samples
+			tr.ClippedSamples += c.ClippedSamples
+			tr.ClippedRuns += c.ClippedRuns
+			tr.DCOffset = append(tr.DCOffset, roundPercent(c.DCOffset))
+			ar.MaxDCOffset = max(ar.MaxDCOffset, roundPercent(math.Abs(c.DCOffset)))
+		}
+		tr.Clipping = ratioPercent(tr.ClippedSamples, tr.Samples)
+
+		ar.Samples += tr.Samples
+		ar.ClippedSamples += tr.ClippedSamples
+		ar.ClippedRuns += tr.ClippedRuns
+		if tr.ClippedRuns > 0 {
+			ar.ClippedTracks++
+		}
+		ar.Tracks = append(ar.Tracks, tr)
+	}
+	if len(ar.Tracks) == 0 {
+		return
+	}
+	ar.Clipping = ratioPercent(ar.ClippedSamples, ar.Samples)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.albums = append(r.albums, ar)
+}
+
+// write the report of every album, ordered by path, to the file.
+func (r *report) write(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	albums := slices.SortedFunc(slices.Values(r.albums), func(a, b albumReport) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	if albums == nil {
+		albums = []albumReport{}
+	}
+
+	data, err := json.MarshalIndent(jsonReport{Albums: albums}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // the report is meant to be readable by everyone, like the tracks themselves
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func ratioPercent(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return roundPercent(float64(n) / float64(total))
+}
@@ -0,0 +1,170 @@
+// Package resolution estimates the true resolution of audio - the bits actually used by the samples, and the sample
+// rate the audio was band limited to - to spot audio padded or upsampled from a lower resolution, or transcoded from a
+// lossy format.
+package resolution
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+
+	"github.com/wjam/flac-check/internal/music/fft"
+)
+
+const (
+	// fftSize is the number of samples in each block the spectrum is measured over.
+	fftSize = 4096
+	// bands is the number of bands the spectrum is averaged into, to smooth out individual notes.
+	bands = 256
+	// transitionBands is the number of bands a low pass filter is expected to fall off within.
+	transitionBands = 8
+	// wallDrop is how far, in dB, the spectrum has to fall off within the transition bands - and stay below for every
+	// band above - for the audio to have been low pass filtered.
+	wallDrop = 40
+	// lossyShare is the share of the Nyquist frequency of the estimated sample rate below which a cutoff is from the
+	// low pass filter of a lossy encoder, rather than the anti-aliasing filter of the original recording.
+	lossyShare = 0.9
+	// minCutoff is the frequency, in Hz, below which a cliff in the spectrum is more likely to be audio without any
+	// high frequencies, such as a solo instrument, than a low pass filter - which lossy encoders keep above this.
+	minCutoff = 11000
+)
+
+// sampleRates returns the sample rates music is commonly released at, from lowest to highest.
+func sampleRates() []int {
+	return []int{44100, 48000, 88200, 96000, 176400, 192000}
+}
+
+// Result is the estimated true resolution of some audio.
+type Result struct {
+	// BitDepth is the number of bits used by the samples, with any padding below them ignored
+	BitDepth int
+	// SampleRate is the lowest common sample rate which could hold all the frequencies in the audio
+	SampleRate int
+	// Cutoff is the frequency, in Hz, above which there's no audio or 0 if the audio goes up to the Nyquist frequency
+	Cutoff float64
+	// Lossy is whether the cutoff is typical of a lossy encoder
+	Lossy bool
+}
+
+// String formats the resolution such as "16-bit/44.1kHz".
+func (r Result) String() string {
+	kHz := strconv.FormatFloat(float64(r.SampleRate)/1000, 'f', -1, 64) //nolint:mnd // Hz to kHz
+	s := fmt.Sprintf("%d-bit/%skHz", r.BitDepth, kHz)
+	if r.Lossy {
+		s += " (lossy)"
+	}
+	return s
+}
+
+// Analyzer analyses audio a frame at a time.
+type Analyzer struct {
+	sampleRate int
+	bitDepth   int
+	// used has every bit used by any sample set
+	used int32
+	// block is the channels mixed to mono, until there are enough samples for a block of the spectrum
+	block []float64
+	// power is the sum of the power of each frequency bin over every block
+	power  []float64
+	blocks int
+	fft    *fft.FFT
+}
+
+func NewAnalyzer(sampleRate, bitDepth int) *Analyzer {
+	return &Analyzer{
+		sampleRate: sampleRate,
+		bitDepth:   bitDepth,
+		block:      make([]float64, 0, fftSize),
+		power:      make([]float64, fftSize/2),
+		fft:        fft.New(fft.Hann(fftSize)),
+	}
+}
+
+// Write adds samples to the analysis - one slice of samples per channel.
+func (a *Analyzer) Write(samples [][]int32) {
+	for i := range samples[0] {
+		var mono float64
+		for _, channel := range samples {
+			a.used |= channel[i]
+			mono += float64(channel[i])
+		}
+		a.block = append(a.block, mono/float64(len(samples)))
+
+		if len(a.block) == fftSize {
+			a.completeBlock()
+		}
+	}
+}
+
+func (a *Analyzer) completeBlock() {
+	for i, power := range a.fft.Power(a.block) {
+		a.power[i] += power
+	}
+	a.blocks++
+	a.block = a.block[:0]
+}
+
+// Result returns the resolution of all the audio written so far, unless it's only silence.
+func (a *Analyzer) Result() (Result, bool) {
+	if a.used == 0 {
+		return Result{}, false
+	}
+
+	r := Result{
+		BitDepth:   a.bitDepth - bits.TrailingZeros32(uint32(a.used)), //nolint:gosec // only the bits matter
+		SampleRate: a.sampleRate,
+	}
+
+	cutoff, ok := a.cutoff()
+	if !ok {
+		return r, true
+	}
+
+	r.Cutoff = cutoff
+	r.SampleRate = estimateSampleRate(cutoff, a.sampleRate)
+	r.Lossy = cutoff < lossyShare*float64(r.SampleRate)/2
+	return r, true
+}
+
+// cutoff finds the highest band where the spectrum falls off a cliff, as low pass filters do, with nothing above it.
+// Only cliffs above minCutoff count.
+func (a *Analyzer) cutoff() (float64, bool) {
+	if a.blocks == 0 {
+		return 0, false
+	}
+
+	binsPerBand := len(a.power) / bands
+	levels := make([]float64, bands)
+	for b := range levels {
+		var sum float64
+		for _, power := range a.power[b*binsPerBand : (b+1)*binsPerBand] {
+			sum += power
+		}
+		// Adding a power of 1, well below the quantisation noise, keeps bins without any audio finite
+		levels[b] = 10 * math.Log10(1+sum/float64(a.blocks*binsPerBand)) //nolint:mnd // power to decibels
+	}
+
+	bandWidth := float64(a.sampleRate) / 2 / bands
+	for b := bands - transitionBands - 1; b >= 0 && float64(b+1)*bandWidth >= minCutoff; b-- {
+		if levels[b]-slices.Max(levels[b+transitionBands:]) >= wallDrop {
+			return float64(b+1) * bandWidth, true
+		}
+	}
+	return 0, false
+}
+
+// estimateSampleRate returns the lowest common sample rate, below the actual sample rate, with a Nyquist frequency at
+// or above the cutoff.
+func estimateSampleRate(cutoff float64, actual int) int {
+	for _, rate := range sampleRates() {
+		if rate >= actual {
+			break
+		}
+		if float64(rate)/2 >= cutoff {
+			return rate
+		}
+	}
+	return actual
+}
@@ -7,10 +7,14 @@ import (
 	"io/fs"
 	"log/slog"
 	"maps"
+	"math"
 	"slices"
 
 	"github.com/wjam/flac-check/internal/logging"
-	"github.com/wjam/flac-check/internal/lrclib"
+	"github.com/wjam/flac-check/internal/music/cover"
+	"github.com/wjam/flac-check/internal/music/discid"
+	"github.com/wjam/flac-check/internal/music/loudness"
+	"github.com/wjam/flac-check/internal/music/resolution"
 	"github.com/wjam/flac-check/internal/music/track"
 	"github.com/wjam/flac-check/internal/music/vorbis"
 	"github.com/wjam/flac-check/internal/musicbrainz"
@@ -29,6 +33,53 @@ func (s *Scan) handleAlbum(ctx context.Context, root string, files []fs.DirEntry
 		return nil
 	}
 
+	var sidecar *sidecarCover
+	if s.opts.SidecarCover {
+		if sidecar, err = readSidecarCover(root, files); err != nil {
+			return err
+		}
+		if err := sidecar.embed(ctx, album); err != nil {
+			return err
+		}
+	}
+
+	single, ok, err := readSingleFileAlbum(root, files, album)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return s.handleSingleFileAlbum(ctx, single, sidecar)
+	}
+
+	if s.opts.ComputeDiscID {
+		s.addComputedDiscIDs(ctx, album)
+	}
+
+	if s.opts.ReplayGain {
+		if err := s.addReplayGain(ctx, album); err != nil {
+			return err
+		}
+	}
+
+	if s.opts.DetectSilence {
+		if err := s.warnSilentTracks(ctx, album); err != nil {
+			return err
+		}
+	}
+
+	if s.opts.AnalyzeAudio {
+		if err := s.analyzeAudio(ctx, album); err != nil {
+			return err
+		}
+		s.report.add(root, album)
+	}
+
+	if s.opts.AcoustIDKey != "" {
+		if err := s.addAcoustIDs(ctx, album); err != nil {
+			return err
+		}
+	}
+
 	var errs []error
 
 	for _, m := range album {
@@ -38,7 +89,20 @@ func (s *Scan) handleAlbum(ctx context.Context, root string, files []fs.DirEntry
 		}
 	}
 
-	errs = append(errs, album.validateTags(s.opts.SilenceAlbumTracks)...)
+	silent := album.configuredSilentTracks(s.opts.SilenceAlbumTracks)
+	if err := s.addMusicBrainzSilentTracks(ctx, album, silent); err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, album.validateTags(silent)...)
+	errs = append(errs, album.validateAudioProperties(s.opts.MinTrackDuration)...)
+	errs = append(errs, album.validateAudioAnalysis(s.opts.MaxClipping, s.opts.MaxDCOffset)...)
+
+	if sidecar != nil {
+		if err := sidecar.validate(album, s.opts.CoverHash, s.opts.CoverHashDistance); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
 	if len(errs) > 0 {
 		return errors.Join(errs...)
@@ -49,12 +113,154 @@ func (s *Scan) handleAlbum(ctx context.Context, root string, files []fs.DirEntry
 		errs = append(errs, t.Save(ctx, s.opts.Write))
 	}
 
+	if sidecar != nil {
+		errs = append(errs, sidecar.create(ctx, album, s.opts.Write))
+	}
+
 	return errors.Join(errs...)
 }
 
+// handleSingleFileAlbum fixes the album wide tags of the single file, before validating the tags of each of the
+// tracks within it.
+func (s *Scan) handleSingleFileAlbum(ctx context.Context, a *singleFileAlbum, sidecar *sidecarCover) error {
+	file := a.file
+	ctx = logging.WithAttrs(ctx, slog.String("track", file.String()))
+
+	if skipped := s.singleFileSkippedChecks(); len(skipped) > 0 {
+		logging.FromContext(ctx).WarnContext(
+			ctx, "Skipping checks which aren't supported for single file albums", slog.Any("checks", skipped),
+		)
+	}
+
+	if err := s.handleSingleFile(ctx, file); err != nil {
+		return fmt.Errorf("failed to handle track %s: %w", file, err)
+	}
+
+	tracks, err := a.tracks()
+	if err != nil {
+		return err
+	}
+
+	if err := tracks.validate(tracks.configuredSilentTracks(s.opts.SilenceAlbumTracks)); err != nil {
+		return err
+	}
+
+	if errs := tracks.validateAudioProperties(s.opts.MinTrackDuration); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if sidecar != nil {
+		if err := sidecar.validate([]*track.Track{file}, s.opts.CoverHash, s.opts.CoverHashDistance); err != nil {
+			return err
+		}
+	}
+
+	if err := file.Save(ctx, s.opts.Write); err != nil {
+		return err
+	}
+
+	if sidecar != nil {
+		return sidecar.create(ctx, []*track.Track{file}, s.opts.Write)
+	}
+
+	return nil
+}
+
+// singleFileSkippedChecks returns the flags of the checks turned on which are only run against a file per track, as
+// they decode each track or write tags & lyrics the tracks of a single file album don't have a place for. Fetching
+// lyrics is on by default, so isn't included.
+func (s *Scan) singleFileSkippedChecks() []string {
+	var skipped []string
+	for flag, enabled := range map[string]bool{
+		"replaygain":      s.opts.ReplayGain,
+		"detect-silence":  s.opts.DetectSilence,
+		"analyze-audio":   s.opts.AnalyzeAudio,
+		"acoustid-key":    s.opts.AcoustIDKey != "",
+		"validate-lyrics": s.opts.ValidateLyrics,
+	} {
+		if enabled {
+			skipped = append(skipped, flag)
+		}
+	}
+	slices.Sort(skipped)
+	return skipped
+}
+
+// handleSingleFile is the equivalent of handleTrack for a single file album, leaving out anything which is specific
+// to a track.
+func (s *Scan) handleSingleFile(ctx context.Context, file *track.Track) error {
+	file.CorrectTags()
+
+	if s.opts.Write {
+		file.CorrectPicture()
+	}
+
+	if s.opts.CopyCueSheetTags {
+		file.CopyCueSheetTags()
+	}
+
+	if s.opts.ComputeDiscID {
+		disc, ok := discNumber(file)
+		if !ok {
+			disc = 1
+		}
+		s.addComputedDiscID(ctx, disc, []*track.Track{file})
+	}
+
+	if err := s.addMusicBrainzAlbumID(ctx, file); err != nil {
+		return err
+	}
+
+	if !file.HasPicture() {
+		if err := s.addFrontCoverToTrack(ctx, file); err != nil {
+			return err
+		}
+	}
+
+	if err := s.checkCoverPolicy(ctx, file); err != nil {
+		return err
+	}
+
+	if s.opts.CheckReleaseCover {
+		if err := s.checkReleaseCover(ctx, file); err != nil {
+			return err
+		}
+	}
+
+	if err := s.addPicturesOfTypes(ctx, file); err != nil {
+		return err
+	}
+
+	if err := s.checkPictures(file); err != nil {
+		return err
+	}
+
+	if err := s.checkSeekTable(file); err != nil {
+		return err
+	}
+
+	if err := s.checkLayout(file); err != nil {
+		return err
+	}
+
+	if !file.HasGenre() {
+		return s.addGenreTag(ctx, file)
+	}
+
+	return nil
+}
+
 func (s *Scan) handleTrack(ctx context.Context, track *track.Track) error {
 	track.CorrectTags()
 
+	if s.opts.Write {
+		track.CorrectPicture()
+	}
+
+	if s.opts.CopyCueSheetTags {
+		track.CopyCueSheetTags()
+	}
+
 	if err := s.addMusicBrainzAlbumID(ctx, track); err != nil {
 		return err
 	}
@@ -69,19 +275,82 @@ func (s *Scan) handleTrack(ctx context.Context, track *track.Track) error {
 		}
 	}
 
+	if err := s.checkCoverPolicy(ctx, track); err != nil {
+		return err
+	}
+
+	if s.opts.CheckReleaseCover {
+		if err := s.checkReleaseCover(ctx, track); err != nil {
+			return err
+		}
+	}
+
+	if err := s.addPicturesOfTypes(ctx, track); err != nil {
+		return err
+	}
+
+	if err := s.checkPictures(track); err != nil {
+		return err
+	}
+
 	if !track.HasGenre() {
 		if err := s.addGenreTag(ctx, track); err != nil {
 			return err
 		}
 	}
 
+	if err := s.checkEmbeddedLyrics(ctx, track); err != nil {
+		return err
+	}
+
 	if !track.HasLyrics() && s.opts.FetchLyrics {
 		if err := s.addLyricsToTrack(ctx, track); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	if s.opts.ValidateLyrics {
+		if s.opts.Write {
+			track.CorrectLyrics()
+		}
+		if err := track.ValidateLyrics(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.checkSeekTable(track); err != nil {
+		return err
+	}
+
+	return s.checkLayout(track)
+}
+
+// checkSeekTable validates the seek points of the SEEKTABLE of the file, generating a new SEEKTABLE first with --write
+// if it's missing or invalid.
+func (s *Scan) checkSeekTable(t *track.Track) error {
+	if !s.opts.ValidateSeekTable {
+		return nil
+	}
+
+	if s.opts.Write {
+		if err := t.CorrectSeekTable(s.opts.SeekTableInterval); err != nil {
+			return err
+		}
+	}
+	return t.ValidateSeekTable()
+}
+
+// checkLayout validates the order & number of the metadata blocks of the file, normalising the layout first with
+// --write.
+func (s *Scan) checkLayout(t *track.Track) error {
+	if !s.opts.ValidateLayout {
+		return nil
+	}
+
+	if s.opts.Write {
+		t.NormaliseLayout(s.opts.AllowedApplications)
+	}
+	return t.ValidateLayout(s.opts.AllowedApplications)
 }
 
 func (s *Scan) addMusicBrainzAlbumID(ctx context.Context, tr *track.Track) error {
@@ -89,12 +358,16 @@ func (s *Scan) addMusicBrainzAlbumID(ctx context.Context, tr *track.Track) error
 		return nil
 	}
 
-	v, ok := tr.TagOk(vorbis.MusicBrainzDiscIDTag)
-	if !ok || len(v) != 1 {
+	var discID, toc string
+	if t, ok := tr.DiscTOC(); ok {
+		discID, toc = t.ID(), t.String()
+	} else if v, ok := tr.TagOk(vorbis.MusicBrainzDiscIDTag); ok && len(v) == 1 {
+		discID = v[0]
+	} else {
 		return nil
 	}
 
-	rel, err := s.music.GetReleaseFromDiscID(ctx, v[0])
+	rel, err := s.music.GetReleaseFromDiscID(ctx, discID, toc)
 	if err != nil {
 		if errors.Is(err, musicbrainz.ErrNoReleaseFound) {
 			logging.FromContext(ctx).InfoContext(ctx, "Unable to populate musicbrainz album ID")
@@ -108,6 +381,82 @@ func (s *Scan) addMusicBrainzAlbumID(ctx context.Context, tr *track.Track) error
 	return nil
 }
 
+// addComputedDiscIDs calculates the MusicBrainz disc ID of tracks that don't have one, either from an embedded
+// cuesheet or from the length of every track on the disc.
+func (s *Scan) addComputedDiscIDs(ctx context.Context, a album) {
+	for disc, tracks := range a.discs() {
+		s.addComputedDiscID(ctx, disc, tracks)
+	}
+}
+
+func (s *Scan) addComputedDiscID(ctx context.Context, disc int, tracks []*track.Track) {
+	var needed []*track.Track
+	for _, t := range tracks {
+		if _, ok := t.TagOk(vorbis.MusicBrainzDiscIDTag); ok {
+			continue
+		}
+		if _, ok := t.TagOk(vorbis.MusicBrainzAlbumIDTag); ok && !s.opts.WriteDiscID {
+			continue
+		}
+		needed = append(needed, t)
+	}
+	if len(needed) == 0 {
+		return
+	}
+
+	toc, err := calculateTOC(tracks)
+	if err != nil {
+		if !errors.Is(err, track.ErrNoStreamInfo) {
+			logging.FromContext(ctx).DebugContext(ctx, "Unable to calculate disc ID",
+				slog.Int("disc", disc),
+				slog.String("error", err.Error()),
+			)
+		}
+		return
+	}
+
+	logging.FromContext(ctx).DebugContext(ctx, "Calculated disc ID",
+		slog.Int("disc", disc),
+		slog.String("discid", toc.ID()),
+		slog.String("toc", toc.String()),
+	)
+	for _, t := range needed {
+		t.SetMusicBrainzDiscID(toc, s.opts.WriteDiscID)
+	}
+}
+
+// calculateTOC works out the table of contents of a disc, preferring an embedded cuesheet and otherwise
+// assuming the tracks were ripped back-to-back from a CD with a standard lead-in.
+func calculateTOC(tracks []*track.Track) (discid.TOC, error) {
+	for _, t := range tracks {
+		if cue := t.CueSheet(); cue != nil {
+			return discid.FromCueSheet(cue)
+		}
+	}
+
+	var lengths []int64
+	for i, t := range tracks {
+		info := t.StreamInfo()
+		if info == nil {
+			return discid.TOC{}, track.ErrNoStreamInfo
+		}
+		if info.SampleRate != discid.CompactDiscSampleRate {
+			return discid.TOC{}, fmt.Errorf("sample rate %d isn't from a CD", info.SampleRate)
+		}
+		if n, _ := trackNumber(t); n != i+1 {
+			return discid.TOC{}, errMissingDiscTracks
+		}
+		lengths = append(lengths, info.SampleCount)
+	}
+
+	if total, ok := trackTotal(tracks[0]); ok && total != len(tracks) {
+		return discid.TOC{}, errMissingDiscTracks
+	}
+
+	return discid.FromTrackLengths(lengths)
+}
+
+// addFrontCoverToTrack sets the front cover from the first cover source which has one.
 func (s *Scan) addFrontCoverToTrack(ctx context.Context, tr *track.Track) error {
 	albumID, ok := tr.TagOk(vorbis.MusicBrainzAlbumIDTag)
 	if !ok {
@@ -119,32 +468,222 @@ func (s *Scan) addFrontCoverToTrack(ctx context.Context, tr *track.Track) error
 		return err
 	}
 
-	var cover string
-	if rel.CoverArtArchive.Count != 0 {
-		var err error
-		cover, err = s.art.GetCoverArtFromMusicBrainzReleaseID(ctx, rel.ID)
+	query := CoverQuery{Release: rel}
+	if v, ok := tr.TagOk(vorbis.AlbumArtistTag); ok && len(v) == 1 {
+		query.Artist = v[0]
+	} else if v, ok := tr.TagOk(vorbis.ArtistTag); ok && len(v) == 1 {
+		query.Artist = v[0]
+	}
+	if v, ok := tr.TagOk(vorbis.AlbumTag); ok && len(v) == 1 {
+		query.Album = v[0]
+	}
+
+	for _, source := range s.covers {
+		cover, ok, err := source.FrontCover(ctx, query)
 		if err != nil {
 			return err
 		}
-	} else {
-		var err error
-		cover, err = s.wiki.GetCoverArtFromMusicBrainzReleaseID(ctx, rel.ID)
-		if err != nil {
-			return err
+		if ok {
+			return tr.SetPicture(cover.Data, cover.URL)
 		}
 	}
 
-	if cover == "" {
-		return errors.New("unable to find cover art")
+	return errors.New("unable to find cover art")
+}
+
+// checkCoverPolicy validates the front cover against the cover policy, replacing it with a fixed version of the
+// original cover art if enabled.
+func (s *Scan) checkCoverPolicy(ctx context.Context, tr *track.Track) error {
+	if s.opts.CoverPolicy.IsZero() {
+		return nil
+	}
+
+	data, ok := tr.PictureData()
+	if !ok {
+		return nil
+	}
+
+	errs := s.opts.CoverPolicy.Validate(data)
+	if len(errs) == 0 || !s.opts.FixCover {
+		return errors.Join(errs...)
+	}
+
+	original, url, err := s.originalCover(ctx, tr, data)
+	if err != nil {
+		return err
+	}
+
+	fixed, err := s.opts.CoverPolicy.Fix(original)
+	if err != nil {
+		return err
+	}
+
+	// Fixing can only shrink the cover art, so it may still not meet the policy
+	if errs := s.opts.CoverPolicy.Validate(fixed); len(errs) > 0 {
+		return errors.Join(errs...)
 	}
 
-	data, err := s.art.FetchImage(ctx, cover)
+	logging.FromContext(ctx).DebugContext(ctx, "Fixed cover art",
+		slog.Int("bytes", len(original)),
+		slog.Int("fixed_bytes", len(fixed)),
+	)
+
+	return tr.SetPicture(fixed, url)
+}
+
+// originalCover fetches the front cover as originally uploaded to the Cover Art Archive, falling back to the given
+// cover art if the release doesn't have any.
+func (s *Scan) originalCover(ctx context.Context, tr *track.Track, current []byte) ([]byte, string, error) {
+	albumID, ok := tr.TagOk(vorbis.MusicBrainzAlbumIDTag)
+	if !ok {
+		return current, "", nil
+	}
+
+	rel, err := s.music.GetReleaseFromReleaseID(ctx, albumID[0])
+	if err != nil {
+		return nil, "", err
+	}
+	if rel.CoverArtArchive.Count == 0 {
+		return current, "", nil
+	}
+
+	url, err := s.art.GetOriginalCoverArtFromMusicBrainzReleaseID(ctx, rel.ID)
+	if err != nil || url == "" {
+		return current, "", err
+	}
+
+	data, err := s.art.FetchImage(ctx, url)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, url, nil
+}
+
+// checkReleaseCover compares the perceptual hash of the front cover against the front cover of the release in the
+// Cover Art Archive, to catch cover art from a different album.
+func (s *Scan) checkReleaseCover(ctx context.Context, tr *track.Track) error {
+	data, ok := tr.PictureData()
+	if !ok {
+		return nil
+	}
+
+	albumID, ok := tr.TagOk(vorbis.MusicBrainzAlbumIDTag)
+	if !ok {
+		return nil
+	}
+
+	rel, err := s.music.GetReleaseFromReleaseID(ctx, albumID[0])
 	if err != nil {
 		return err
 	}
-	return tr.SetPicture(data, cover)
+	if rel.CoverArtArchive.Count == 0 {
+		return nil
+	}
+
+	url, err := s.art.GetCoverArtFromMusicBrainzReleaseID(ctx, rel.ID)
+	if err != nil || url == "" {
+		return err
+	}
+
+	want, err := s.hashes.Get(url, func() (cover.Hash, error) {
+		release, err := s.art.FetchImage(ctx, url)
+		if err != nil {
+			return 0, err
+		}
+		return s.opts.CoverHash.Hash(release)
+	})
+	if err != nil {
+		return err
+	}
+
+	got, err := s.opts.CoverHash.Hash(data)
+	if err != nil {
+		return err
+	}
+
+	if distance := want.Distance(got); distance > s.opts.CoverHashDistance {
+		return CoverMismatchError{ReleaseID: rel.ID, Distance: distance}
+	}
+
+	return nil
+}
+
+// addPicturesOfTypes fetches any missing pictures of the types to fetch from the Cover Art Archive.
+func (s *Scan) addPicturesOfTypes(ctx context.Context, tr *track.Track) error {
+	if len(s.opts.FetchPictureTypes) == 0 {
+		return nil
+	}
+
+	albumID, ok := tr.TagOk(vorbis.MusicBrainzAlbumIDTag)
+	if !ok {
+		return nil
+	}
+
+	var rel *musicbrainz.Release
+	for _, pictureType := range s.opts.FetchPictureTypes {
+		if slices.Contains(tr.PictureTypes(), pictureType) {
+			continue
+		}
+		imageType, ok := cover.CoverArtArchiveType(pictureType)
+		if !ok {
+			continue
+		}
+
+		if rel == nil {
+			r, err := s.music.GetReleaseFromReleaseID(ctx, albumID[0])
+			if err != nil {
+				return err
+			}
+			rel = &r
+		}
+		if rel.CoverArtArchive.Count == 0 {
+			return nil
+		}
+
+		url, err := s.art.GetCoverArtOfTypeFromMusicBrainzReleaseID(ctx, rel.ID, imageType)
+		if err != nil {
+			return err
+		}
+		if url == "" {
+			logging.FromContext(ctx).DebugContext(ctx, "No cover art of type found", slog.String("type", imageType))
+			continue
+		}
+
+		data, err := s.art.FetchImage(ctx, url)
+		if err != nil {
+			return err
+		}
+		if err := tr.AddPicture(pictureType, data, url); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkPictures validates the number & types of pictures against the picture rules.
+func (s *Scan) checkPictures(tr *track.Track) error {
+	types := tr.PictureTypes()
+
+	var errs []error
+	if s.opts.MaxPictures > 0 && len(types) > s.opts.MaxPictures {
+		errs = append(errs, TooManyPicturesError{Count: len(types), Max: s.opts.MaxPictures})
+	}
+
+	if len(s.opts.AllowedPictureTypes) > 0 {
+		for _, pictureType := range types {
+			if !slices.Contains(s.opts.AllowedPictureTypes, pictureType) {
+				errs = append(errs, PictureTypeNotAllowedError{Type: pictureType})
+			}
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
+// addLyricsToTrack sets the lyrics from the first lyrics provider with synced lyrics, falling back to the plain
+// lyrics of the first provider with any.
 func (s *Scan) addLyricsToTrack(ctx context.Context, meta *track.Track) error {
 	title, ok := meta.TagOk(vorbis.TitleTag)
 	if !ok || len(title) != 1 {
@@ -158,31 +697,78 @@ func (s *Scan) addLyricsToTrack(ctx context.Context, meta *track.Track) error {
 	if !ok || len(album) != 1 {
 		return nil
 	}
-	lyrics, err := s.lyrics.FindLyricsForTrack(ctx, title[0], artist[0], album[0])
-	if err != nil {
-		if errors.Is(err, lrclib.ErrNoLyricsFound) {
-			logging.FromContext(ctx).DebugContext(ctx, "No lyrics found")
-			return nil
+
+	query := LyricsQuery{Title: title[0], Artist: artist[0], Album: album[0], Path: meta.Path()}
+	if duration, ok := meta.Duration(); ok {
+		query.Duration = duration
+	}
+	languages := s.opts.Allowed(meta)
+
+	var plain Lyrics
+	var plainSource string
+	for _, provider := range s.lyricProviders {
+		lyrics, ok, err := provider.Lyrics(ctx, query)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if lyrics.Instrumental {
+			break
+		}
+
+		if err := s.checkFetchedLyrics(meta, lyrics); err != nil {
+			logging.FromContext(ctx).InfoContext(ctx, "Skipped suspicious lyrics",
+				slog.String("provider", provider.Name()),
+				slog.String("reason", err.Error()),
+			)
+			continue
+		}
+
+		if lyrics.Synced != "" {
+			return meta.SetSyncedLyrics(ctx, lyrics.Synced, languages, provider.Name())
+		}
+		if lyrics.Plain != "" && plainSource == "" {
+			plain, plainSource = lyrics, provider.Name()
 		}
-		return err
 	}
 
-	if lyrics.Instrumental {
-		logging.FromContext(ctx).DebugContext(ctx, "No lyrics found")
-		return nil
+	if plainSource != "" {
+		return meta.SetUnsyncedLyrics(ctx, plain.Plain, languages, plainSource)
 	}
 
-	international := slices.Contains(s.opts.InternationalArtists, artist[0])
+	logging.FromContext(ctx).DebugContext(ctx, "No lyrics found")
+	return nil
+}
 
-	if lyrics.SyncedLyrics != "" {
-		meta.SetSyncedLyrics(ctx, lyrics.SyncedLyrics, international)
-		return nil
-	} else if lyrics.PlainLyrics != "" {
-		meta.SetUnsyncedLyrics(ctx, lyrics.PlainLyrics, international)
+// checkFetchedLyrics checks the synced & plain lyrics from a lyrics provider aren't suspicious.
+func (s *Scan) checkFetchedLyrics(meta *track.Track, lyrics Lyrics) error {
+	var errs []error
+	for _, l := range []string{lyrics.Synced, lyrics.Plain} {
+		if l != "" {
+			errs = append(errs, meta.CheckLyrics(l))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkEmbeddedLyrics reports or removes the lyrics already in the track if they're suspicious, depending on the
+// options.
+func (s *Scan) checkEmbeddedLyrics(ctx context.Context, meta *track.Track) error {
+	if s.opts.SuspiciousLyrics == SuspiciousLyricsIgnore {
 		return nil
 	}
 
-	return errors.New("lyrics was empty")
+	err := meta.CheckEmbeddedLyrics(s.opts.ValidateLyrics)
+	if err == nil || s.opts.SuspiciousLyrics == SuspiciousLyricsReport {
+		return err
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "Removing suspicious lyrics", slog.String("reason", err.Error()))
+	meta.RemoveLyrics()
+	return nil
 }
 
 func (s *Scan) addGenreTag(ctx context.Context, tr *track.Track) error {
@@ -208,3 +794,110 @@ func (s *Scan) addGenreTag(ctx context.Context, tr *track.Track) error {
 
 	return nil
 }
+
+// addReplayGain measures the loudness of each track, and of the album as a whole, to set the ReplayGain tags.
+func (s *Scan) addReplayGain(ctx context.Context, a album) error {
+	measurements := make([]loudness.Measurement, 0, len(a))
+	for _, t := range a {
+		m, err := t.MeasureLoudness()
+		if err != nil {
+			return fmt.Errorf("failed to measure loudness of track %s: %w", t, err)
+		}
+		measurements = append(measurements, m)
+	}
+
+	albumMeasurement := loudness.Combine(measurements...)
+	logging.FromContext(ctx).DebugContext(ctx, "Measured album loudness",
+		slog.Float64("loudness", albumMeasurement.Loudness()),
+		slog.Float64("peak", albumMeasurement.Peak),
+	)
+
+	for i, t := range a {
+		t.SetReplayGain(measurements[i], albumMeasurement)
+	}
+
+	return nil
+}
+
+// warnSilentTracks decodes each track to warn about any which are silent, as in no louder than the silence threshold.
+func (s *Scan) warnSilentTracks(ctx context.Context, a album) error {
+	for _, t := range a {
+		peak, err := t.SamplePeak()
+		if err != nil {
+			return fmt.Errorf("failed to decode track %s: %w", t, err)
+		}
+
+		level := 20 * math.Log10(peak) //nolint:mnd // amplitude to decibels
+		if level > s.opts.SilenceThreshold {
+			continue
+		}
+
+		logging.FromContext(ctx).WarnContext(ctx, "Track is silent",
+			slog.String("track", t.String()),
+			slog.String("peak", fmt.Sprintf("%.1f dBFS", level)),
+		)
+	}
+
+	return nil
+}
+
+// analyzeAudio decodes each track to warn about any with a lower resolution than its STREAMINFO block claims, such as
+// 16 bit audio padded to 24 bits, or audio upsampled from a lower sample rate or transcoded from a lossy format. The
+// analysis is kept on each track for validating clipping & DC offset.
+func (s *Scan) analyzeAudio(ctx context.Context, a album) error {
+	for _, t := range a {
+		analysis, err := t.AnalyzeAudio()
+		if err != nil {
+			return fmt.Errorf("failed to decode track %s: %w", t, err)
+		}
+		if analysis.Resolution == nil {
+			continue
+		}
+		r := *analysis.Resolution
+
+		if s.opts.ResolutionTag {
+			t.SetEstimatedResolution(r)
+		}
+
+		info := t.StreamInfo()
+		if r.BitDepth >= info.BitDepth && r.SampleRate >= info.SampleRate && !r.Lossy {
+			continue
+		}
+
+		attrs := []any{
+			slog.String("track", t.String()),
+			slog.String("resolution", r.String()),
+			slog.String("claimed", resolution.Result{BitDepth: info.BitDepth, SampleRate: info.SampleRate}.String()),
+		}
+		if r.Cutoff > 0 {
+			attrs = append(attrs, slog.String("cutoff", fmt.Sprintf("%.1f kHz", r.Cutoff/1000))) //nolint:mnd // Hz to kHz
+		}
+		logging.FromContext(ctx).WarnContext(ctx, "Track has a lower resolution than it claims", attrs...)
+	}
+
+	return nil
+}
+
+// addMusicBrainzSilentTracks checks MusicBrainz for whether any missing tracks are silence or data tracks, which
+// are commonly left out when ripping a CD.
+func (s *Scan) addMusicBrainzSilentTracks(ctx context.Context, a album, silent silentTracks) error {
+	if len(a.missingTrackNumbers(silent)) == 0 {
+		return nil
+	}
+
+	albumID := a.getTag(vorbis.MusicBrainzAlbumIDTag)
+	if len(albumID) != 1 {
+		return nil
+	}
+
+	rel, err := s.music.GetReleaseTracksFromReleaseID(ctx, albumID[0])
+	if err != nil {
+		return err
+	}
+
+	for disc, positions := range rel.SilentTracks() {
+		silent[disc] = append(silent[disc], positions...)
+	}
+
+	return nil
+}
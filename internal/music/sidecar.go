@@ -0,0 +1,153 @@
+package music
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/logging"
+	"github.com/wjam/flac-check/internal/music/cover"
+	"github.com/wjam/flac-check/internal/music/track"
+)
+
+// sidecarCoverNames are the names of the cover art files, alongside the tracks, that players & servers such as
+// Navidrome look for - in order of preference.
+func sidecarCoverNames() []string {
+	return []string{"cover.jpg", "folder.jpg", "cover.png", "folder.png"}
+}
+
+// sidecarCover is the cover art file in the album directory, with no data if the album doesn't have one.
+type sidecarCover struct {
+	root string
+	path string
+	data []byte
+}
+
+// readSidecarCover reads the sidecar cover of the album, if it has one.
+func readSidecarCover(root string, files []fs.DirEntry) (*sidecarCover, error) {
+	for _, name := range sidecarCoverNames() {
+		for _, f := range files {
+			if !strings.EqualFold(f.Name(), name) {
+				continue
+			}
+
+			path := filepath.Join(root, f.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return &sidecarCover{root: root, path: path, data: data}, nil
+		}
+	}
+
+	return &sidecarCover{root: root}, nil
+}
+
+// embed uses the sidecar cover as the front cover of any track without a picture.
+func (c *sidecarCover) embed(ctx context.Context, tracks []*track.Track) error {
+	if c.data == nil {
+		return nil
+	}
+
+	for _, t := range tracks {
+		if t.HasPicture() {
+			continue
+		}
+
+		logging.FromContext(ctx).DebugContext(ctx, "Embedding sidecar cover",
+			slog.String("track", t.String()),
+			slog.String("file", c.path),
+		)
+		if err := t.SetPicture(c.data, c.path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate checks the front cover of each track is the same image as the sidecar cover, allowing for differences
+// from resizing or re-encoding.
+func (c *sidecarCover) validate(tracks []*track.Track, algorithm cover.Algorithm, maxDistance int) error {
+	if c.data == nil {
+		return nil
+	}
+
+	want, err := algorithm.Hash(c.data)
+	if err != nil {
+		return fmt.Errorf("failed to read sidecar cover %s: %w", c.path, err)
+	}
+
+	var different []string
+	for _, t := range tracks {
+		data, ok := t.PictureData()
+		if !ok {
+			continue
+		}
+
+		got, err := algorithm.Hash(data)
+		if err != nil {
+			return fmt.Errorf("failed to read front cover of track %s: %w", t, err)
+		}
+
+		if want.Distance(got) > maxDistance {
+			different = append(different, t.String())
+		}
+	}
+
+	if len(different) > 0 {
+		return SidecarCoverMismatchError{Sidecar: filepath.Base(c.path), Tracks: different}
+	}
+
+	return nil
+}
+
+// create extracts the front cover of the album into a sidecar cover, if the album doesn't already have one.
+func (c *sidecarCover) create(ctx context.Context, tracks []*track.Track, write bool) error {
+	if c.data != nil {
+		return nil
+	}
+
+	for _, t := range tracks {
+		data, ok := t.PictureData()
+		if !ok {
+			continue
+		}
+
+		meta, err := cover.ReadMetadata(data)
+		if err != nil {
+			return fmt.Errorf("failed to read front cover of track %s: %w", t, err)
+		}
+
+		var name string
+		switch meta.MIME {
+		case "image/jpeg":
+			name = "cover.jpg"
+		case "image/png":
+			name = "cover.png"
+		default:
+			return fmt.Errorf("unable to create sidecar cover from %s front cover", meta.MIME)
+		}
+		path := filepath.Join(c.root, name)
+
+		if !write {
+			logging.FromContext(ctx).WarnContext(ctx, "Missing sidecar cover", slog.String("file", path))
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		logging.FromContext(ctx).WarnContext(ctx, "Saving sidecar cover", slog.String("file", path))
+
+		//nolint:gosec // the cover art is meant to be read by other programs
+		return os.WriteFile(path, data, 0o644)
+	}
+
+	return nil
+}
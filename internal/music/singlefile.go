@@ -0,0 +1,157 @@
+package music
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/music/cuesheet"
+	"github.com/wjam/flac-check/internal/music/track"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+)
+
+// singleFileAlbum is an album ripped as one FLAC file for the whole CD, with the tracks described by a cue sheet.
+type singleFileAlbum struct {
+	file *track.Track
+	// sheet is the cue sheet text, either from a sidecar file or the CUESHEET tag. It is nil when the tracks are only
+	// described by an embedded CUESHEET block.
+	sheet *cuesheet.Sheet
+	// sidecar is the path of the cue sheet file, if there is one.
+	sidecar string
+}
+
+// readSingleFileAlbum checks whether an album is a single file album - one FLAC file without a TRACKNUMBER tag
+// along with either a cue sheet file, a CUESHEET tag or an embedded cuesheet of more than one track.
+func readSingleFileAlbum(root string, files []fs.DirEntry, a album) (*singleFileAlbum, bool, error) {
+	if len(a) != 1 {
+		return nil, false, nil
+	}
+	file := a[0]
+	if _, ok := file.TagOk(vorbis.TrackNumberTag); ok {
+		return nil, false, nil
+	}
+
+	var sidecars []string
+	for _, f := range files {
+		if strings.EqualFold(filepath.Ext(f.Name()), ".cue") {
+			sidecars = append(sidecars, f.Name())
+		}
+	}
+	if len(sidecars) > 1 {
+		return nil, false, fmt.Errorf("expected a single cue sheet, got %s", join(sidecars))
+	}
+
+	s := &singleFileAlbum{file: file}
+
+	var sheet []byte
+	if len(sidecars) == 1 {
+		s.sidecar = filepath.Join(root, sidecars[0])
+		var err error
+		if sheet, err = os.ReadFile(s.sidecar); err != nil {
+			return nil, false, err
+		}
+	} else if v, ok := file.TagOk(vorbis.CueSheetTag); ok && len(v) == 1 {
+		sheet = []byte(v[0])
+	}
+
+	if sheet == nil {
+		cue := file.CueSheet()
+		return s, cue != nil && len(cue.AudioTracks()) > 1, nil
+	}
+
+	var err error
+	if s.sheet, err = cuesheet.ParseSheet(sheet); err != nil {
+		return nil, false, err
+	}
+
+	info := file.StreamInfo()
+	if info == nil {
+		return nil, false, track.ErrNoStreamInfo
+	}
+	file.SetCueSheet(s.sheet.CueSheet(info.SampleRate, uint64(info.SampleCount))) //nolint:gosec // 36 bit field
+
+	return s, true, nil
+}
+
+// tracks splits the single file into virtual tracks using the cuesheet. Any gap before a track is kept at the end of
+// the previous track, and anything before the first track is kept at the start of the first track.
+func (s *singleFileAlbum) tracks() (album, error) {
+	info := s.file.StreamInfo()
+	if info == nil || info.SampleCount == 0 {
+		return nil, track.ErrNoStreamInfo
+	}
+	total := uint64(info.SampleCount)
+
+	var cueTracks []cuesheet.Track
+	for _, t := range s.file.CueSheet().AudioTracks() {
+		if t.IsAudio {
+			cueTracks = append(cueTracks, t)
+		}
+	}
+
+	tracks := make(album, 0, len(cueTracks))
+	for i, t := range cueTracks {
+		var start uint64
+		if i > 0 {
+			start = t.StartOffset()
+		}
+		end := total
+		if i+1 < len(cueTracks) {
+			end = cueTracks[i+1].StartOffset()
+		}
+		if start >= end {
+			return nil, fmt.Errorf("cuesheet track %d is beyond the end of the audio", t.Number)
+		}
+
+		tracks = append(tracks, track.NewVirtualTrack(s.file, int(t.Number), start, end, s.trackTags(t, len(cueTracks))))
+	}
+
+	return tracks, nil
+}
+
+// trackTags returns the tags for a track of the album, taken from the cue sheet where the file doesn't already have
+// an album wide value.
+func (s *singleFileAlbum) trackTags(t cuesheet.Track, total int) map[vorbis.Tag][]string {
+	tags := map[vorbis.Tag][]string{
+		vorbis.TrackNumberTag: {strconv.Itoa(int(t.Number))},
+		vorbis.TrackTotalTag:  {strconv.Itoa(total)},
+	}
+	if t.ISRC != "" {
+		tags[vorbis.ISRCTag] = []string{t.ISRC}
+	}
+
+	addIfMissing := func(tag vorbis.Tag, value string) {
+		if _, ok := s.file.TagOk(tag); !ok && value != "" {
+			tags[tag] = []string{value}
+		}
+	}
+
+	// A single file is a single disc
+	addIfMissing(vorbis.DiscNumberTag, "1")
+
+	if s.sheet == nil {
+		return tags
+	}
+
+	addIfMissing(vorbis.AlbumTag, s.sheet.Title)
+	addIfMissing(vorbis.ArtistTag, s.sheet.Performer)
+	addIfMissing(vorbis.DateTag, s.sheet.Date)
+	addIfMissing(vorbis.GenreTag, s.sheet.Genre)
+
+	st, ok := s.sheet.Track(int(t.Number))
+	if !ok {
+		return tags
+	}
+	if st.Title != "" {
+		tags[vorbis.TitleTag] = []string{st.Title}
+	}
+	if st.Performer != "" && st.Performer != s.sheet.Performer {
+		tags[vorbis.ArtistTag] = []string{st.Performer}
+		addIfMissing(vorbis.AlbumArtistTag, s.sheet.Performer)
+	}
+
+	return tags
+}
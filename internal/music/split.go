@@ -0,0 +1,180 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/logging"
+	"github.com/wjam/flac-check/internal/music/track"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+	"github.com/wjam/flac-check/internal/walk"
+)
+
+type SplitOptions struct {
+	Write bool
+	// RemoveOriginal removes the single file album & its cue sheet once the split tracks have been verified against it
+	RemoveOriginal bool
+}
+
+// Split splits single file albums into a FLAC file per track.
+type Split struct {
+	path string
+	opts SplitOptions
+}
+
+func NewSplit(path string, opts SplitOptions) *Split {
+	return &Split{
+		path: path,
+		opts: opts,
+	}
+}
+
+func (s *Split) Run(ctx context.Context) error {
+	var errs []error
+	for e, err := range walk.DirIter(s.path) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !e.Entry.IsDir() {
+			continue
+		}
+
+		entries, err := os.ReadDir(e.Path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		files := filesOnly(entries)
+
+		if len(files) != len(entries) {
+			continue
+		}
+
+		if err := s.splitAlbum(ctx, e.Path, files); err != nil {
+			errs = append(errs, fmt.Errorf("album %s: %w", e.Path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Split) splitAlbum(ctx context.Context, root string, files []fs.DirEntry) error {
+	ctx = logging.WithAttrs(ctx, slog.String("path", root))
+	album, err := readAllFlacTracks(ctx, root, files)
+	if err != nil {
+		return err
+	}
+
+	single, ok, err := readSingleFileAlbum(root, files, album)
+	if err != nil || !ok {
+		return err
+	}
+
+	logging.FromContext(ctx).DebugContext(ctx, "Splitting album")
+
+	tracks, err := single.tracks()
+	if err != nil {
+		return err
+	}
+
+	// Only split albums with complete tags, so the tracks are usable once split
+	if err := tracks.validate(nil); err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, t := range tracks {
+		path := filepath.Join(root, splitFileName(t))
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("split track %s already exists", path)
+		}
+		paths = append(paths, path)
+	}
+
+	for i, t := range tracks {
+		ctx := logging.WithAttrs(ctx, slog.String("track", t.String()))
+		if err := t.SaveAs(ctx, paths[i], s.opts.Write); err != nil {
+			err = fmt.Errorf("failed to split track %s: %w", t, err)
+			if s.opts.Write {
+				err = errors.Join(err, removeSplitTracks(ctx, paths[:i+1]))
+			}
+			return err
+		}
+	}
+
+	if !s.opts.Write || !s.opts.RemoveOriginal {
+		return nil
+	}
+
+	// The original is the only lossless copy of the audio, so is only removed once the split tracks decode to exactly
+	// the audio they were cut from
+	if err := verifySplitTracks(single.file, paths); err != nil {
+		return errors.Join(
+			fmt.Errorf("failed to verify split tracks: %w", err),
+			removeSplitTracks(ctx, paths),
+		)
+	}
+
+	originals := []string{filepath.Join(root, single.file.String())}
+	if single.sidecar != "" {
+		originals = append(originals, single.sidecar)
+	}
+	for _, path := range originals {
+		logging.FromContext(ctx).WarnContext(ctx, "Removing split file", slog.String("file", path))
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifySplitTracks reads back the split tracks to check they decode to the audio of the single file album.
+func verifySplitTracks(original *track.Track, paths []string) error {
+	var split []*track.Track
+	for _, path := range paths {
+		t, err := track.NewTrack(path)
+		if err != nil {
+			return err
+		}
+		split = append(split, t)
+	}
+	return original.VerifySplit(split)
+}
+
+// removeSplitTracks removes the tracks written while splitting an album which couldn't be split, so the album isn't
+// left half split.
+func removeSplitTracks(ctx context.Context, paths []string) error {
+	var errs []error
+	for _, path := range paths {
+		logging.FromContext(ctx).WarnContext(ctx, "Removing split track", slog.String("file", path))
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// splitFileName names a split track by its track number and title, replacing characters which commonly aren't
+// allowed in file names.
+func splitFileName(t *track.Track) string {
+	title := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, t.Tag(vorbis.TitleTag)[0])
+
+	number, _ := trackNumber(t)
+
+	return fmt.Sprintf("%02d - %s.flac", number, title)
+}
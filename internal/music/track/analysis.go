@@ -0,0 +1,58 @@
+package track
+
+import (
+	"github.com/wjam/flac-check/internal/music/analysis"
+	"github.com/wjam/flac-check/internal/music/audio"
+	"github.com/wjam/flac-check/internal/music/resolution"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+)
+
+// AudioAnalysis is what was found by decoding the audio of a track.
+type AudioAnalysis struct {
+	// Resolution is the estimated true resolution, unless the track is only silence
+	Resolution *resolution.Result
+	Channels   []analysis.Channel
+}
+
+// AnalyzeAudio decodes the audio of the track to estimate its true resolution and measure any clipping & DC offset,
+// keeping the result for AudioAnalysis.
+func (t *Track) AnalyzeAudio() (*AudioAnalysis, error) {
+	if t.streamInfo == nil {
+		return nil, ErrNoStreamInfo
+	}
+
+	analyzer := resolution.NewAnalyzer(t.streamInfo.SampleRate, t.streamInfo.BitDepth)
+	meter := analysis.NewMeter(t.streamInfo.BitDepth, t.streamInfo.ChannelCount)
+	for f, err := range audio.Frames(t.audio) {
+		if err != nil {
+			return nil, err
+		}
+		samples := make([][]int32, 0, len(f.Subframes))
+		for _, sub := range f.Subframes {
+			samples = append(samples, sub.Samples)
+		}
+		analyzer.Write(samples)
+		meter.Write(samples)
+	}
+
+	a := &AudioAnalysis{Channels: meter.Channels()}
+	if r, ok := analyzer.Result(); ok {
+		a.Resolution = &r
+	}
+	t.analysis = a
+
+	return a, nil
+}
+
+// AudioAnalysis returns the analysis of the audio of the track, or nil if it hasn't been analysed.
+func (t *Track) AudioAnalysis() *AudioAnalysis {
+	return t.analysis
+}
+
+// SetEstimatedResolution sets the tag recording the estimated true resolution of the track.
+func (t *Track) SetEstimatedResolution(r resolution.Result) {
+	value := r.String()
+	if v, ok := t.TagOk(vorbis.EstimatedResolutionTag); !ok || len(v) != 1 || v[0] != value {
+		t.newTags[vorbis.EstimatedResolutionTag] = []string{value}
+	}
+}
@@ -1,13 +1,22 @@
 package track
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/wjam/flac-check/internal/music/cover"
 	"github.com/wjam/flac-check/internal/music/vorbis"
+
+	"github.com/go-flac/flacpicture/v2"
 )
 
+// ErrNoStreamInfo is returned when the audio of a track is needed, but it doesn't have a STREAMINFO block describing
+// it.
+var ErrNoStreamInfo = errors.New("track doesn't have a STREAMINFO block")
+
 var _ error = InvalidTagValueError{}
 
 type InvalidTagValueError struct {
@@ -56,6 +65,88 @@ func (e InvalidIntTagError) Is(err error) bool {
 	return e.Tag == e2.Tag && slices.Equal(e.Values, e2.Values)
 }
 
+var _ error = CueSheetTrackCountError{}
+
+type CueSheetTrackCountError struct {
+	CueSheetTracks int
+	TrackTotal     string
+}
+
+func (e CueSheetTrackCountError) Error() string {
+	return fmt.Sprintf("cuesheet has %d tracks but %q is %q", e.CueSheetTracks, vorbis.TrackTotalTag, e.TrackTotal)
+}
+
+func (e CueSheetTrackCountError) Is(err error) bool {
+	e2, ok := err.(CueSheetTrackCountError)
+	if !ok {
+		return false
+	}
+	return e.CueSheetTracks == e2.CueSheetTracks && e.TrackTotal == e2.TrackTotal
+}
+
+var _ error = CueSheetLeadOutError{}
+
+type CueSheetLeadOutError struct {
+	LeadOut      uint64
+	TotalSamples int64
+}
+
+func (e CueSheetLeadOutError) Error() string {
+	return fmt.Sprintf("cuesheet lead-out is at sample %d but the track has %d samples", e.LeadOut, e.TotalSamples)
+}
+
+func (e CueSheetLeadOutError) Is(err error) bool {
+	e2, ok := err.(CueSheetLeadOutError)
+	if !ok {
+		return false
+	}
+	return e.LeadOut == e2.LeadOut && e.TotalSamples == e2.TotalSamples
+}
+
+var _ error = InvalidMediaCatalogNumberError{}
+
+type InvalidMediaCatalogNumberError struct {
+	Value string
+}
+
+func (e InvalidMediaCatalogNumberError) Error() string {
+	return fmt.Sprintf("expected cuesheet media catalog number to be a valid barcode, got %q", e.Value)
+}
+
+func (e InvalidMediaCatalogNumberError) Is(err error) bool {
+	e2, ok := err.(InvalidMediaCatalogNumberError)
+	if !ok {
+		return false
+	}
+	return e.Value == e2.Value
+}
+
+var _ error = MismatchedISRCError{}
+
+type MismatchedISRCError struct {
+	TrackNumber int
+	CueSheet    string
+	Tags        []string
+}
+
+func (e MismatchedISRCError) Error() string {
+	return fmt.Sprintf(
+		"cuesheet ISRC %q for track %d doesn't match %q tag, got %s",
+		e.CueSheet,
+		e.TrackNumber,
+		vorbis.ISRCTag,
+		join(e.Tags),
+	)
+}
+
+func (e MismatchedISRCError) Is(err error) bool {
+	e2, ok := err.(MismatchedISRCError)
+	if !ok {
+		return false
+	}
+	return e.TrackNumber == e2.TrackNumber && e.CueSheet == e2.CueSheet && slices.Equal(e.Tags, e2.Tags)
+}
+
 func join(s []string) string {
 	if s == nil {
 		return "<nil>"
@@ -68,3 +159,283 @@ func join(s []string) string {
 
 	return strings.Join(s, ",")
 }
+
+var _ error = PictureMIMEError{}
+
+type PictureMIMEError struct {
+	Stored string
+	Actual string
+}
+
+func (e PictureMIMEError) Error() string {
+	return fmt.Sprintf("incorrect picture type %s - should be %s", e.Stored, e.Actual)
+}
+
+func (e PictureMIMEError) Is(err error) bool {
+	e2, ok := err.(PictureMIMEError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = PictureMetadataError{}
+
+type PictureMetadataError struct {
+	Field  string
+	Stored uint32
+	Actual uint32
+}
+
+func (e PictureMetadataError) Error() string {
+	return fmt.Sprintf("incorrect picture %s %d - should be %d", e.Field, e.Stored, e.Actual)
+}
+
+func (e PictureMetadataError) Is(err error) bool {
+	e2, ok := err.(PictureMetadataError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = DuplicatePictureError{}
+
+type DuplicatePictureError struct {
+	Type  flacpicture.PictureType
+	Count int
+}
+
+func (e DuplicatePictureError) Error() string {
+	return fmt.Sprintf("expected at most 1 %s picture, got %d", cover.TypeName(e.Type), e.Count)
+}
+
+func (e DuplicatePictureError) Is(err error) bool {
+	e2, ok := err.(DuplicatePictureError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = MisplacedLyricsError{}
+
+type MisplacedLyricsError struct {
+	Tag    vorbis.Tag
+	Synced bool
+}
+
+func (e MisplacedLyricsError) Error() string {
+	if e.Synced {
+		return fmt.Sprintf("expected unsynced lyrics in %q, got synced lyrics", e.Tag)
+	}
+	return fmt.Sprintf("expected synced lyrics in %q, got unsynced lyrics", e.Tag)
+}
+
+func (e MisplacedLyricsError) Is(err error) bool {
+	e2, ok := err.(MisplacedLyricsError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = LyricsLongerThanTrackError{}
+
+type LyricsLongerThanTrackError struct {
+	Last     time.Duration
+	Duration time.Duration
+}
+
+func (e LyricsLongerThanTrackError) Error() string {
+	return fmt.Sprintf("lyrics go on until %s but the track is only %s long", e.Last, e.Duration)
+}
+
+func (e LyricsLongerThanTrackError) Is(err error) bool {
+	e2, ok := err.(LyricsLongerThanTrackError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = LyricsHeaderMismatchError{}
+
+type LyricsHeaderMismatchError struct {
+	Header string
+	Value  string
+	Tag    vorbis.Tag
+	Values []string
+}
+
+func (e LyricsHeaderMismatchError) Error() string {
+	return fmt.Sprintf("lyrics header %q is %q which doesn't match %q, got %s", e.Header, e.Value, e.Tag, join(e.Values))
+}
+
+func (e LyricsHeaderMismatchError) Is(err error) bool {
+	e2, ok := err.(LyricsHeaderMismatchError)
+	if !ok {
+		return false
+	}
+	return e.Header == e2.Header && e.Value == e2.Value && e.Tag == e2.Tag && slices.Equal(e.Values, e2.Values)
+}
+
+var _ error = PlaceholderLyricsError{}
+
+type PlaceholderLyricsError struct {
+	Lyrics string
+}
+
+func (e PlaceholderLyricsError) Error() string {
+	return fmt.Sprintf("expected lyrics, got placeholder %q", e.Lyrics)
+}
+
+func (e PlaceholderLyricsError) Is(err error) bool {
+	e2, ok := err.(PlaceholderLyricsError)
+	if !ok {
+		return false
+	}
+	return e == e2
+}
+
+var _ error = BlockOrderError{}
+
+// BlockOrderError is a metadata block which isn't where it has to be in the file.
+type BlockOrderError struct {
+	Block       string
+	Expectation string
+}
+
+func (e BlockOrderError) Error() string {
+	return fmt.Sprintf("expected %s metadata block %s", e.Block, e.Expectation)
+}
+
+func (e BlockOrderError) Is(err error) bool {
+	e2, ok := err.(BlockOrderError)
+	if !ok {
+		return false
+	}
+	return e.Block == e2.Block && e.Expectation == e2.Expectation
+}
+
+var _ error = BlockCountError{}
+
+// BlockCountError is a type of metadata block which is missing or duplicated.
+type BlockCountError struct {
+	Block       string
+	Count       int
+	Expectation string
+}
+
+func (e BlockCountError) Error() string {
+	return fmt.Sprintf("expected %s %s metadata block, got %d", e.Expectation, e.Block, e.Count)
+}
+
+func (e BlockCountError) Is(err error) bool {
+	e2, ok := err.(BlockCountError)
+	if !ok {
+		return false
+	}
+	return e.Block == e2.Block && e.Count == e2.Count && e.Expectation == e2.Expectation
+}
+
+var _ error = UnexpectedBlockError{}
+
+// UnexpectedBlockError is a metadata block which shouldn't be in the file - an APPLICATION block which isn't allowed
+// or a block of a reserved type.
+type UnexpectedBlockError struct {
+	Block string
+}
+
+func (e UnexpectedBlockError) Error() string {
+	return fmt.Sprintf("unexpected %s metadata block", e.Block)
+}
+
+func (e UnexpectedBlockError) Is(err error) bool {
+	e2, ok := err.(UnexpectedBlockError)
+	if !ok {
+		return false
+	}
+	return e.Block == e2.Block
+}
+
+var _ error = SeekTableLengthError{}
+
+// SeekTableLengthError is a SEEKTABLE metadata block which isn't made up of whole seek points.
+type SeekTableLengthError struct {
+	Length int
+}
+
+func (e SeekTableLengthError) Error() string {
+	return fmt.Sprintf("expected SEEKTABLE of %d byte seek points, got %d bytes", seekPointSize, e.Length)
+}
+
+func (e SeekTableLengthError) Is(err error) bool {
+	e2, ok := err.(SeekTableLengthError)
+	if !ok {
+		return false
+	}
+	return e.Length == e2.Length
+}
+
+var _ error = SeekPointError{}
+
+// SeekPointError is a seek point of the SEEKTABLE which a player can't use to seek with.
+type SeekPointError struct {
+	Index   int
+	Sample  uint64
+	Problem string
+}
+
+func (e SeekPointError) Error() string {
+	return fmt.Sprintf("seek point %d for sample %d %s", e.Index, e.Sample, e.Problem)
+}
+
+func (e SeekPointError) Is(err error) bool {
+	e2, ok := err.(SeekPointError)
+	if !ok {
+		return false
+	}
+	return e.Index == e2.Index && e.Sample == e2.Sample && e.Problem == e2.Problem
+}
+
+var _ error = AudioMD5Error{}
+
+// AudioMD5Error is audio which doesn't decode to the MD5 in the STREAMINFO block.
+type AudioMD5Error struct {
+	Expected string
+	Actual   string
+}
+
+func (e AudioMD5Error) Error() string {
+	return fmt.Sprintf("expected audio to have MD5 %s, got %s", e.Expected, e.Actual)
+}
+
+func (e AudioMD5Error) Is(err error) bool {
+	e2, ok := err.(AudioMD5Error)
+	if !ok {
+		return false
+	}
+	return e.Expected == e2.Expected && e.Actual == e2.Actual
+}
+
+var _ error = SplitSampleCountError{}
+
+// SplitSampleCountError is tracks split from a single file album which don't have as many samples between them as the
+// album.
+type SplitSampleCountError struct {
+	Expected int64
+	Actual   int64
+}
+
+func (e SplitSampleCountError) Error() string {
+	return fmt.Sprintf("expected split tracks to have %d samples, got %d", e.Expected, e.Actual)
+}
+
+func (e SplitSampleCountError) Is(err error) bool {
+	e2, ok := err.(SplitSampleCountError)
+	if !ok {
+		return false
+	}
+	return e.Expected == e2.Expected && e.Actual == e2.Actual
+}
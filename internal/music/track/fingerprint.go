@@ -0,0 +1,53 @@
+package track
+
+import (
+	"github.com/wjam/flac-check/internal/music/audio"
+	"github.com/wjam/flac-check/internal/music/chromaprint"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+)
+
+// Fingerprint decodes the start of the audio of the track to calculate its Chromaprint fingerprint, in the form
+// AcoustID looks fingerprints up by.
+func (t *Track) Fingerprint() (string, error) {
+	if t.streamInfo == nil {
+		return "", ErrNoStreamInfo
+	}
+
+	fingerprinter := chromaprint.NewFingerprinter(t.streamInfo.SampleRate, t.streamInfo.BitDepth)
+	for f, err := range audio.Frames(t.audio) {
+		if err != nil {
+			return "", err
+		}
+		samples := make([][]int32, 0, len(f.Subframes))
+		for _, sub := range f.Subframes {
+			samples = append(samples, sub.Samples)
+		}
+		fingerprinter.Write(samples)
+		if fingerprinter.Done() {
+			break
+		}
+	}
+
+	fingerprint, err := fingerprinter.Fingerprint()
+	if err != nil {
+		return "", err
+	}
+	return chromaprint.Encode(fingerprint), nil
+}
+
+// SetAcoustID sets the AcoustID of the track along with the fingerprint it was found by.
+func (t *Track) SetAcoustID(id, fingerprint string) {
+	for tag, value := range map[vorbis.Tag]string{
+		vorbis.AcoustIDTag:            id,
+		vorbis.AcoustIDFingerprintTag: fingerprint,
+	} {
+		if v, ok := t.TagOk(tag); !ok || len(v) != 1 || v[0] != value {
+			t.newTags[tag] = []string{value}
+		}
+	}
+}
+
+// SetMusicBrainzTrackID sets the MusicBrainz recording of the track.
+func (t *Track) SetMusicBrainzTrackID(id string) {
+	t.newTags[vorbis.MusicBrainzTrackIDTag] = []string{id}
+}
@@ -0,0 +1,183 @@
+package track
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-flac/go-flac/v2"
+)
+
+// DefaultApplications returns the APPLICATION blocks allowed unless configured otherwise - those flac keeps the chunks
+// of the original WAVE, AIFF or Wave64 file in with --keep-foreign-metadata, so the original file can be restored.
+func DefaultApplications() []string {
+	return []string{"riff", "aiff", "w64 "}
+}
+
+// blockOrder returns the canonical order of the types of metadata block, which the layout is normalised to.
+// STREAMINFO has to be first, and the SEEKTABLE comes before the bulk of the metadata so players find it without
+// reading the pictures. PADDING is last so tags can grow into it.
+func blockOrder() []flac.BlockType {
+	return []flac.BlockType{
+		flac.StreamInfo,
+		flac.SeekTable,
+		flac.VorbisComment,
+		flac.CueSheet,
+		flac.Application,
+		flac.Picture,
+		flac.Padding,
+	}
+}
+
+// blockHeaderSize is the number of bytes before the data of every metadata block.
+const blockHeaderSize = 4
+
+func blockTypeName(t flac.BlockType) string {
+	//nolint:exhaustive // reserved & invalid types are named by number
+	switch t {
+	case flac.StreamInfo:
+		return "STREAMINFO"
+	case flac.Padding:
+		return "PADDING"
+	case flac.Application:
+		return "APPLICATION"
+	case flac.SeekTable:
+		return "SEEKTABLE"
+	case flac.VorbisComment:
+		return "VORBIS_COMMENT"
+	case flac.CueSheet:
+		return "CUESHEET"
+	case flac.Picture:
+		return "PICTURE"
+	default:
+		return fmt.Sprintf("reserved type %d", t)
+	}
+}
+
+// applicationID is the registered ID of the application an APPLICATION block is for.
+func applicationID(b *flac.MetaDataBlock) string {
+	return string(b.Data[:min(len(b.Data), 4)]) //nolint:mnd // 32 bit ID
+}
+
+// allowedBlock is whether a metadata block is one that can be in the file - any of the defined types other than
+// APPLICATION blocks for applications which aren't allowed.
+func allowedBlock(b *flac.MetaDataBlock, applications []string) bool {
+	if b.Type == flac.Application {
+		return slices.Contains(applications, applicationID(b))
+	}
+	return slices.Contains(blockOrder(), b.Type)
+}
+
+// NormaliseLayout reorders the metadata blocks into the canonical layout when the track is saved, removing blocks
+// which aren't allowed and merging PADDING blocks into one.
+func (t *Track) NormaliseLayout(applications []string) {
+	if t.flac == nil {
+		return
+	}
+	t.normaliseLayout = true
+	t.applications = applications
+}
+
+// layout returns the metadata blocks the track will be saved with.
+func (t *Track) layout() []*flac.MetaDataBlock {
+	if !t.normaliseLayout {
+		return t.flac.Meta
+	}
+
+	var blocks []*flac.MetaDataBlock
+	var padding *flac.MetaDataBlock
+	for _, b := range t.flac.Meta {
+		switch {
+		case !allowedBlock(b, t.applications):
+			continue
+		case b.Type != flac.Padding:
+			blocks = append(blocks, b)
+		case padding == nil:
+			padding = b
+		default:
+			// The merged block takes up the space of both, including the header of the one dropped
+			padding = &flac.MetaDataBlock{
+				Type: flac.Padding,
+				Data: make(flac.BlockData, len(padding.Data)+blockHeaderSize+len(b.Data)),
+			}
+		}
+	}
+
+	order := blockOrder()
+	slices.SortStableFunc(blocks, func(a, b *flac.MetaDataBlock) int {
+		return slices.Index(order, a.Type) - slices.Index(order, b.Type)
+	})
+	if padding != nil {
+		blocks = append(blocks, padding)
+	}
+	return blocks
+}
+
+// layoutChanged is whether normalising the layout changes the metadata blocks.
+func (t *Track) layoutChanged() bool {
+	return t.normaliseLayout && !slices.Equal(t.flac.Meta, t.layout())
+}
+
+func layoutNames(blocks []*flac.MetaDataBlock) string {
+	names := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		names = append(names, blockTypeName(b.Type))
+	}
+	return strings.Join(names, ",")
+}
+
+// ValidateLayout checks the metadata blocks the track will be saved with - STREAMINFO first, exactly one
+// VORBIS_COMMENT, at most one SEEKTABLE, CUESHEET & PADDING, the SEEKTABLE before any PICTURE, PADDING last, and no
+// APPLICATION blocks which aren't allowed or blocks of a reserved type.
+func (t *Track) ValidateLayout(applications []string) error {
+	if t.flac == nil {
+		return nil
+	}
+
+	blocks := t.layout()
+	counts := map[flac.BlockType]int{}
+	for _, b := range blocks {
+		counts[b.Type]++
+	}
+
+	var errs []error
+	for _, typ := range []flac.BlockType{flac.StreamInfo, flac.VorbisComment} {
+		if counts[typ] != 1 {
+			errs = append(errs, BlockCountError{Block: blockTypeName(typ), Count: counts[typ], Expectation: "exactly one"})
+		}
+	}
+	for _, typ := range []flac.BlockType{flac.SeekTable, flac.CueSheet, flac.Padding} {
+		if counts[typ] > 1 {
+			errs = append(errs, BlockCountError{Block: blockTypeName(typ), Count: counts[typ], Expectation: "at most one"})
+		}
+	}
+
+	if counts[flac.StreamInfo] > 0 && blocks[0].Type != flac.StreamInfo {
+		errs = append(errs, BlockOrderError{Block: blockTypeName(flac.StreamInfo), Expectation: "first"})
+	}
+
+	seekTable := slices.IndexFunc(blocks, func(b *flac.MetaDataBlock) bool { return b.Type == flac.SeekTable })
+	picture := slices.IndexFunc(blocks, func(b *flac.MetaDataBlock) bool { return b.Type == flac.Picture })
+	if seekTable != -1 && picture != -1 && seekTable > picture {
+		errs = append(errs, BlockOrderError{Block: blockTypeName(flac.SeekTable), Expectation: "before any PICTURE"})
+	}
+
+	padding := slices.IndexFunc(blocks, func(b *flac.MetaDataBlock) bool { return b.Type == flac.Padding })
+	if padding != -1 && padding != len(blocks)-1 {
+		errs = append(errs, BlockOrderError{Block: blockTypeName(flac.Padding), Expectation: "last"})
+	}
+
+	for _, b := range blocks {
+		if allowedBlock(b, applications) {
+			continue
+		}
+		name := blockTypeName(b.Type)
+		if b.Type == flac.Application {
+			name = fmt.Sprintf("%s %q", name, applicationID(b))
+		}
+		errs = append(errs, UnexpectedBlockError{Block: name})
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package track
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/wjam/flac-check/internal/music/lrc"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+)
+
+// ValidateLyrics checks LYRICS holds valid synced lyrics, in order and within the duration of the track, and that
+// UNSYNCEDLYRICS doesn't hold synced lyrics.
+func (t *Track) ValidateLyrics() error {
+	var errs []error
+	if synced, ok := t.lyricsTag(vorbis.LyricsTag); ok {
+		lyrics, err := lrc.Parse(synced)
+		if lyrics.Synced() {
+			duration, _ := t.Duration()
+			errs = append(errs, err, lyrics.Validate(duration))
+		} else {
+			errs = append(errs, MisplacedLyricsError{Tag: vorbis.LyricsTag, Synced: false})
+		}
+	}
+
+	if unsynced, ok := t.lyricsTag(vorbis.UnsyncedLyricsTag); ok && lrc.IsSynced(unsynced) {
+		errs = append(errs, MisplacedLyricsError{Tag: vorbis.UnsyncedLyricsTag, Synced: true})
+	}
+
+	return errors.Join(errs...)
+}
+
+// CorrectLyrics moves lyrics into the right tag, if the other one is empty, and sorts synced lyrics which are out of
+// order or have the wrong [length:] header. Synced lyrics which can't be fully parsed are left alone.
+func (t *Track) CorrectLyrics() {
+	synced, hasSynced := t.lyricsTag(vorbis.LyricsTag)
+	unsynced, hasUnsynced := t.lyricsTag(vorbis.UnsyncedLyricsTag)
+
+	switch {
+	case hasSynced && !hasUnsynced && !lrc.IsSynced(synced):
+		t.newTags[vorbis.UnsyncedLyricsTag] = []string{synced}
+		t.newTags[vorbis.LyricsTag] = []string{}
+		return
+	case hasUnsynced && !hasSynced && lrc.IsSynced(unsynced):
+		t.newTags[vorbis.LyricsTag] = []string{unsynced}
+		t.newTags[vorbis.UnsyncedLyricsTag] = []string{}
+		synced, hasSynced = unsynced, true
+	}
+
+	if !hasSynced {
+		return
+	}
+
+	lyrics, err := lrc.Parse(synced)
+	if err != nil || !lyrics.Synced() {
+		return
+	}
+
+	duration, _ := t.Duration()
+	var order lrc.TimestampOrderError
+	var length lrc.LengthHeaderError
+	if err := lyrics.Validate(duration); !errors.As(err, &order) && !errors.As(err, &length) {
+		return
+	}
+
+	lyrics.Fix(duration)
+	t.newTags[vorbis.LyricsTag] = []string{lyrics.String()}
+}
+
+// lyricsTag returns the lyrics in the tag, if there's a single non-blank value.
+func (t *Track) lyricsTag(tag vorbis.Tag) (string, bool) {
+	v, ok := t.TagOk(tag)
+	if !ok || len(v) != 1 || strings.TrimSpace(v[0]) == "" {
+		return "", false
+	}
+	return v[0], true
+}
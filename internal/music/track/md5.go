@@ -0,0 +1,101 @@
+package track
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // the same hash as the STREAMINFO block, for identifying audio rather than security
+	"encoding/hex"
+	"hash"
+
+	"github.com/wjam/flac-check/internal/music/audio"
+)
+
+// AudioMD5 returns the MD5 of the decoded audio - from the STREAMINFO block, or by decoding the audio the same way
+// the encoder would have if the block doesn't have one - or an empty string if the track doesn't have any audio.
+func (t *Track) AudioMD5() (string, error) {
+	if t.streamInfo != nil && !bytes.Equal(t.streamInfo.AudioMD5, make([]byte, md5.Size)) {
+		return hex.EncodeToString(t.streamInfo.AudioMD5), nil
+	}
+
+	sum, frames, err := t.decodeMD5()
+	if err != nil || frames == 0 {
+		return "", err
+	}
+
+	return hex.EncodeToString(sum), nil
+}
+
+// VerifyAudioMD5 decodes the audio to check it matches the MD5 in the STREAMINFO block.
+func (t *Track) VerifyAudioMD5() error {
+	if t.streamInfo == nil {
+		return ErrNoStreamInfo
+	}
+
+	sum, _, err := t.decodeMD5()
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(sum, t.streamInfo.AudioMD5) {
+		return AudioMD5Error{
+			Expected: hex.EncodeToString(t.streamInfo.AudioMD5),
+			Actual:   hex.EncodeToString(sum),
+		}
+	}
+	return nil
+}
+
+// VerifySplit decodes the audio of the tracks split from the track, in order, to check they have as many samples
+// between them as the track and that together they decode to the MD5 of the audio of the track.
+func (t *Track) VerifySplit(split []*Track) error {
+	if t.streamInfo == nil {
+		return ErrNoStreamInfo
+	}
+
+	expected, err := t.AudioMD5()
+	if err != nil {
+		return err
+	}
+
+	sum := md5.New() //nolint:gosec // see import
+	var samples int64
+	for _, s := range split {
+		if s.streamInfo == nil {
+			return ErrNoStreamInfo
+		}
+		samples += s.streamInfo.SampleCount
+		if _, err := s.hashAudio(sum); err != nil {
+			return err
+		}
+	}
+
+	if samples != t.streamInfo.SampleCount {
+		return SplitSampleCountError{Expected: t.streamInfo.SampleCount, Actual: samples}
+	}
+	if actual := hex.EncodeToString(sum.Sum(nil)); actual != expected {
+		return AudioMD5Error{Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// decodeMD5 decodes the audio to calculate its MD5, along with the number of frames decoded.
+func (t *Track) decodeMD5() ([]byte, int, error) {
+	sum := md5.New() //nolint:gosec // see import
+	frames, err := t.hashAudio(sum)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sum.Sum(nil), frames, nil
+}
+
+// hashAudio writes the decoded samples of the audio to the hash, returning the number of frames decoded.
+func (t *Track) hashAudio(h hash.Hash) (int, error) {
+	var frames int
+	for f, err := range audio.Frames(t.audio) {
+		if err != nil {
+			return 0, err
+		}
+		f.Hash(h)
+		frames++
+	}
+	return frames, nil
+}
@@ -0,0 +1,86 @@
+package track
+
+import (
+	"fmt"
+	"strconv"
+
+	errors2 "github.com/wjam/flac-check/internal/errorutil"
+	"github.com/wjam/flac-check/internal/music/audio"
+	"github.com/wjam/flac-check/internal/music/loudness"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+)
+
+// MeasureLoudness decodes the audio of the track to measure its loudness.
+func (t *Track) MeasureLoudness() (loudness.Measurement, error) {
+	if t.streamInfo == nil {
+		return loudness.Measurement{}, ErrNoStreamInfo
+	}
+
+	meter := loudness.NewMeter(t.streamInfo.SampleRate, t.streamInfo.ChannelCount)
+	for f, err := range audio.Frames(t.audio) {
+		if err != nil {
+			return loudness.Measurement{}, err
+		}
+		meter.Write(f.Float(t.streamInfo.BitDepth))
+	}
+
+	return meter.Measurement(), nil
+}
+
+// SetReplayGain sets the ReplayGain 2.0 tags of the track, from the loudness of the track and of the whole album.
+func (t *Track) SetReplayGain(track, album loudness.Measurement) {
+	//nolint:exhaustive // only the ReplayGain tags
+	for tag, value := range map[vorbis.Tag]string{
+		vorbis.ReplayGainTrackGainTag: formatGain(track.ReplayGain()),
+		vorbis.ReplayGainTrackPeakTag: formatPeak(track.Peak),
+		vorbis.ReplayGainAlbumGainTag: formatGain(album.ReplayGain()),
+		vorbis.ReplayGainAlbumPeakTag: formatPeak(album.Peak),
+	} {
+		if v, ok := t.TagOk(tag); !ok || len(v) != 1 || v[0] != value {
+			t.newTags[tag] = []string{value}
+		}
+	}
+}
+
+// validateReplayGain checks a track with any ReplayGain tags has all of them.
+func (t *Track) validateReplayGain() []error {
+	tags := replayGainTags()
+
+	present := false
+	for _, tag := range tags {
+		if _, ok := t.TagOk(tag); ok {
+			present = true
+		}
+	}
+	if !present {
+		return nil
+	}
+
+	var errs []error
+	for _, tag := range tags {
+		if values := t.Tag(tag); len(values) != 1 {
+			errs = append(errs, errors2.NotSingleTagValueError{
+				Tag:    tag,
+				Values: values,
+			})
+		}
+	}
+	return errs
+}
+
+func replayGainTags() []vorbis.Tag {
+	return []vorbis.Tag{
+		vorbis.ReplayGainTrackGainTag,
+		vorbis.ReplayGainTrackPeakTag,
+		vorbis.ReplayGainAlbumGainTag,
+		vorbis.ReplayGainAlbumPeakTag,
+	}
+}
+
+func formatGain(gain float64) string {
+	return fmt.Sprintf("%+.2f dB", gain)
+}
+
+func formatPeak(peak float64) string {
+	return strconv.FormatFloat(peak, 'f', 6, 64) //nolint:mnd // conventional ReplayGain precision
+}
@@ -0,0 +1,207 @@
+package track
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-flac/go-flac/v2"
+
+	"github.com/wjam/flac-check/internal/music/audio"
+)
+
+// DefaultSeekTableInterval is the time between the seek points of a generated SEEKTABLE, matching the default of
+// flac.
+const DefaultSeekTableInterval = 10 * time.Second
+
+const (
+	// seekPointSize is the number of bytes in each seek point.
+	seekPointSize = 18
+	// placeholderSample is the sample number of a placeholder seek point, which is ignored.
+	placeholderSample = 0xFFFFFFFFFFFFFFFF
+)
+
+// SeekPoint is a point in the audio a player can seek to without decoding the audio before it.
+type SeekPoint struct {
+	// Sample is the number of the first sample of the frame.
+	Sample uint64
+	// Offset is the byte offset of the frame from the start of the first frame.
+	Offset uint64
+	// Samples is the number of samples in the frame.
+	Samples uint16
+}
+
+// ParseSeekTable decodes the seek points of a SEEKTABLE metadata block.
+func ParseSeekTable(data []byte) ([]SeekPoint, error) {
+	if len(data)%seekPointSize != 0 {
+		return nil, SeekTableLengthError{Length: len(data)}
+	}
+
+	points := make([]SeekPoint, 0, len(data)/seekPointSize)
+	for p := data; len(p) > 0; p = p[seekPointSize:] {
+		points = append(points, SeekPoint{
+			Sample:  binary.BigEndian.Uint64(p),
+			Offset:  binary.BigEndian.Uint64(p[8:]),  //nolint:mnd // after the 64 bit sample number
+			Samples: binary.BigEndian.Uint16(p[16:]), //nolint:mnd // after the 64 bit offset
+		})
+	}
+	return points, nil
+}
+
+func marshalSeekTable(points []SeekPoint) *flac.MetaDataBlock {
+	data := make([]byte, 0, len(points)*seekPointSize)
+	for _, p := range points {
+		data = binary.BigEndian.AppendUint64(data, p.Sample)
+		data = binary.BigEndian.AppendUint64(data, p.Offset)
+		data = binary.BigEndian.AppendUint16(data, p.Samples)
+	}
+	return &flac.MetaDataBlock{Type: flac.SeekTable, Data: data}
+}
+
+// seekTable returns the SEEKTABLE the track will be saved with, if it has one.
+func (t *Track) seekTable() *flac.MetaDataBlock {
+	if t.newSeekTable != nil {
+		return t.newSeekTable
+	}
+	for _, b := range t.flac.Meta {
+		if b.Type == flac.SeekTable {
+			return b
+		}
+	}
+	return nil
+}
+
+// frameHeaders reads the headers of the audio frames, keeping them for the next time they're needed.
+func (t *Track) frameHeaders() ([]audio.Header, error) {
+	if t.headers != nil {
+		return t.headers, nil
+	}
+
+	headers := []audio.Header{}
+	for h, err := range audio.Headers(t.audio) {
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, h)
+	}
+	t.headers = headers
+	return headers, nil
+}
+
+// ValidateSeekTable checks the track has a SEEKTABLE, and that its seek points are in order, within the total samples
+// of the track and point at the start of a frame - scanning the frame headers to find where each frame is.
+func (t *Track) ValidateSeekTable() error {
+	if t.flac == nil || len(t.audio) == 0 {
+		return nil
+	}
+
+	block := t.seekTable()
+	if block == nil {
+		return BlockCountError{Block: blockTypeName(flac.SeekTable), Count: 0, Expectation: "exactly one"}
+	}
+
+	points, err := ParseSeekTable(block.Data)
+	if err != nil {
+		return err
+	}
+
+	headers, err := t.frameHeaders()
+	if err != nil {
+		return err
+	}
+	frames := map[uint64]audio.Header{}
+	for _, h := range headers {
+		frames[uint64(h.Offset)] = h //nolint:gosec // offsets are never negative
+	}
+
+	var total uint64
+	if t.streamInfo != nil {
+		total = uint64(t.streamInfo.SampleCount) //nolint:gosec // sample counts are never negative
+	}
+
+	var errs []error
+	var previous *SeekPoint
+	placeholders := false
+	for i, p := range points {
+		if p.Sample == placeholderSample {
+			placeholders = true
+			continue
+		}
+
+		if problem := seekPointProblem(p, previous, placeholders, total, frames); problem != "" {
+			errs = append(errs, SeekPointError{Index: i, Sample: p.Sample, Problem: problem})
+		}
+		previous = &p
+	}
+
+	return errors.Join(errs...)
+}
+
+func seekPointProblem(
+	p SeekPoint, previous *SeekPoint, placeholders bool, total uint64, frames map[uint64]audio.Header,
+) string {
+	switch {
+	case placeholders:
+		return "follows a placeholder"
+	case previous != nil && p.Sample <= previous.Sample:
+		return fmt.Sprintf("isn't after the previous point for sample %d", previous.Sample)
+	case total != 0 && p.Sample >= total:
+		return fmt.Sprintf("is beyond the %d samples of the track", total)
+	}
+
+	h, ok := frames[p.Offset]
+	switch {
+	case !ok:
+		return fmt.Sprintf("offset %d isn't the start of a frame", p.Offset)
+	case h.Sample != p.Sample:
+		return fmt.Sprintf("offset %d is the frame starting at sample %d", p.Offset, h.Sample)
+	case h.BlockSize != p.Samples:
+		return fmt.Sprintf("has %d samples rather than the %d of the frame", p.Samples, h.BlockSize)
+	}
+	return ""
+}
+
+// CorrectSeekTable replaces a missing or invalid SEEKTABLE with one generated by scanning the frame headers, with a
+// seek point for the frame at or before every interval of the audio.
+func (t *Track) CorrectSeekTable(interval time.Duration) error {
+	if t.ValidateSeekTable() == nil {
+		return nil
+	}
+	if t.streamInfo == nil {
+		return ErrNoStreamInfo
+	}
+
+	headers, err := t.frameHeaders()
+	if err != nil {
+		return err
+	}
+
+	step := max(1, uint64(interval.Seconds()*float64(t.streamInfo.SampleRate)))
+	var end uint64
+	if len(headers) > 0 {
+		last := headers[len(headers)-1]
+		end = last.Sample + uint64(last.BlockSize)
+	}
+
+	var points []SeekPoint
+	i := 0
+	for target := uint64(0); target < end; target += step {
+		for i+1 < len(headers) && headers[i+1].Sample <= target {
+			i++
+		}
+		h := headers[i]
+		if len(points) > 0 && points[len(points)-1].Sample == h.Sample {
+			// Frames longer than the interval would otherwise get a point for every interval within them
+			continue
+		}
+		points = append(points, SeekPoint{
+			Sample:  h.Sample,
+			Offset:  uint64(h.Offset), //nolint:gosec // offsets are never negative
+			Samples: h.BlockSize,
+		})
+	}
+
+	t.newSeekTable = marshalSeekTable(points)
+	return nil
+}
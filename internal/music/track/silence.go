@@ -0,0 +1,28 @@
+package track
+
+import (
+	"math"
+
+	"github.com/wjam/flac-check/internal/music/audio"
+)
+
+// SamplePeak decodes the audio of the track to find the largest sample, as a ratio of full scale.
+func (t *Track) SamplePeak() (float64, error) {
+	if t.streamInfo == nil {
+		return 0, ErrNoStreamInfo
+	}
+
+	var peak float64
+	for f, err := range audio.Frames(t.audio) {
+		if err != nil {
+			return 0, err
+		}
+		for _, channel := range f.Float(t.streamInfo.BitDepth) {
+			for _, s := range channel {
+				peak = max(peak, math.Abs(s))
+			}
+		}
+	}
+
+	return peak, nil
+}
@@ -0,0 +1,139 @@
+package track
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/wjam/flac-check/internal/music/lrc"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+)
+
+// placeholderLyrics returns what lyrics sites put in place of lyrics they don't have, normalised by normaliseText.
+func placeholderLyrics() []string {
+	return []string{
+		"instrumental",
+		"lyrics not available",
+		"lyrics not found",
+		"no lyrics",
+		"no lyrics available",
+		"no lyrics found",
+		"this song is an instrumental",
+		"we are not licensed to display the full lyrics for this song at the moment",
+	}
+}
+
+// CheckLyrics checks whether the lyrics are probably for a different version of the track - synced lyrics going on
+// after the end of the track or with a title or artist header which doesn't match the tags - or are just a
+// placeholder for missing lyrics.
+func (t *Track) CheckLyrics(lyrics string) error {
+	return t.checkLyrics(lyrics, true)
+}
+
+// CheckEmbeddedLyrics checks the lyrics already in the LYRICS & UNSYNCEDLYRICS tags with CheckLyrics, leaving out
+// whether they go on after the end of the track when the lyrics are validated too, as validating them reports that.
+func (t *Track) CheckEmbeddedLyrics(validated bool) error {
+	var errs []error
+	for _, tag := range []vorbis.Tag{vorbis.LyricsTag, vorbis.UnsyncedLyricsTag} {
+		if lyrics, ok := t.lyricsTag(tag); ok {
+			errs = append(errs, t.checkLyrics(lyrics, !validated))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkLyrics is CheckLyrics, optionally leaving out whether synced lyrics go on after the end of the track.
+func (t *Track) checkLyrics(lyrics string, checkLength bool) error {
+	text := lyrics
+	var errs []error
+	if parsed, _ := lrc.Parse(lyrics); parsed.Synced() {
+		text = lrc.Text(lyrics)
+
+		if duration, ok := t.Duration(); ok && checkLength {
+			last := slices.MaxFunc(parsed.Lines, func(a, b lrc.Line) int {
+				return cmp.Compare(a.Time, b.Time)
+			})
+			if last.Time > duration {
+				errs = append(errs, LyricsLongerThanTrackError{Last: last.Time, Duration: duration})
+			}
+		}
+
+		for _, h := range []struct {
+			header string
+			tag    vorbis.Tag
+		}{
+			{lrc.TitleHeader, vorbis.TitleTag},
+			{lrc.ArtistHeader, vorbis.ArtistTag},
+		} {
+			value, ok := parsed.Header(h.header)
+			values := t.Tag(h.tag)
+			if !ok || value == "" || len(values) == 0 {
+				continue
+			}
+			if !slices.ContainsFunc(values, func(v string) bool { return sameName(value, v) }) {
+				errs = append(errs, LyricsHeaderMismatchError{
+					Header: h.header,
+					Value:  value,
+					Tag:    h.tag,
+					Values: slices.Clone(values),
+				})
+			}
+		}
+	}
+
+	normalised := normaliseText(text)
+	if !strings.ContainsFunc(normalised, unicode.IsLetter) || slices.Contains(placeholderLyrics(), normalised) {
+		errs = append(errs, PlaceholderLyricsError{Lyrics: strings.TrimSpace(lyrics)})
+	}
+
+	return errors.Join(errs...)
+}
+
+// RemoveLyrics removes the lyrics of the track, along with where they came from. The language is left as it's the
+// language of the track rather than just of its lyrics, and may not have been detected from them.
+func (t *Track) RemoveLyrics() {
+	for _, tag := range []vorbis.Tag{
+		vorbis.LyricsTag,
+		vorbis.UnsyncedLyricsTag,
+		vorbis.LyricsSourceTag,
+	} {
+		if _, ok := t.TagOk(tag); ok {
+			t.newTags[tag] = []string{}
+		}
+	}
+}
+
+// sameName is whether two names are the same, ignoring case, punctuation & anything in brackets such as "(Live)", or
+// one contains the other such as an artist "featuring" another.
+func sameName(a, b string) bool {
+	a, b = normaliseName(a), normaliseName(b)
+	if a == "" || b == "" {
+		return a == b
+	}
+	return strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+func normaliseName(name string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			depth = max(0, depth-1)
+		case depth == 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// normaliseText lower-cases the text and reduces it to words separated by single spaces.
+func normaliseText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
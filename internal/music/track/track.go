@@ -14,15 +14,21 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	errors2 "github.com/wjam/flac-check/internal/errorutil"
 	"github.com/wjam/flac-check/internal/logging"
+	"github.com/wjam/flac-check/internal/music/audio"
+	"github.com/wjam/flac-check/internal/music/cover"
+	"github.com/wjam/flac-check/internal/music/cuesheet"
+	"github.com/wjam/flac-check/internal/music/discid"
+	"github.com/wjam/flac-check/internal/music/language"
+	"github.com/wjam/flac-check/internal/music/lrc"
 	"github.com/wjam/flac-check/internal/music/vorbis"
 
 	"github.com/go-flac/flacpicture/v2"
 	"github.com/go-flac/flacvorbis/v2"
 	"github.com/go-flac/go-flac/v2"
-	"golang.org/x/text/encoding/charmap"
 )
 
 type Track struct {
@@ -32,9 +38,31 @@ type Track struct {
 	commentOffset *int
 	picture       *flacpicture.MetadataBlockPicture
 	pictureOffset *int
-	tags          map[string][]string
-	newTags       map[vorbis.Tag][]string
-	newPicture    *flacpicture.MetadataBlockPicture
+	// pictures are all the pictures, of any type, including the front cover
+	pictures   []*flacpicture.MetadataBlockPicture
+	tags       map[string][]string
+	newTags    map[vorbis.Tag][]string
+	newPicture *flacpicture.MetadataBlockPicture
+	// newPictureURL is where the new picture came from, if it's been fetched
+	newPictureURL string
+	addedPictures []addedPicture
+	streamInfo    *flac.StreamInfoBlock
+	cueSheet      *cuesheet.CueSheet
+	discTOC       *discid.TOC
+	audio         []byte
+	// analysis is set once the audio has been analysed
+	analysis *AudioAnalysis
+	// normaliseLayout reorders the metadata blocks when saving, only keeping APPLICATION blocks for the applications
+	normaliseLayout bool
+	applications    []string
+	// newSeekTable replaces the SEEKTABLE, or is added if there isn't one, when saving
+	newSeekTable *flac.MetaDataBlock
+	// headers are the headers of the audio frames, once they've been read
+	headers []audio.Header
+
+	// Set for a virtual track - a single track within a single file album
+	parent     *Track
+	start, end uint64
 }
 
 func NewTrack(path string) (*Track, error) {
@@ -54,7 +82,19 @@ func NewTrack(path string) (*Track, error) {
 		return nil, err
 	}
 
-	pic, pi, err := extractPicture(f)
+	pictures, pic, pi, err := extractPictures(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	info, err := extractStreamInfo(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	cue, err := extractCueSheet(f)
 	if err != nil {
 		_ = f.Close()
 		return nil, err
@@ -77,39 +117,128 @@ func NewTrack(path string) (*Track, error) {
 		commentOffset: ci,
 		picture:       pic,
 		pictureOffset: pi,
+		pictures:      pictures,
 		tags:          tags,
 		newTags:       map[vorbis.Tag][]string{},
+		streamInfo:    info,
+		cueSheet:      cue,
+		audio:         content[audio.AudioOffset(f):],
 	}, nil
 }
 
 func (t *Track) SetPicture(pic []byte, url string) error {
-	mime := http.DetectContentType(pic)
-	if mime != "image/jpeg" && mime != "image/png" {
-		return fmt.Errorf("invalid picture type: %s", mime)
+	block, err := newPictureBlock(flacpicture.PictureTypeFrontCover, pic)
+	if err != nil {
+		return err
 	}
-	img, err := flacpicture.NewFromImageData(flacpicture.PictureTypeFrontCover, url, pic, mime)
+
+	t.newPicture = block
+	t.newPictureURL = url
+
+	return nil
+}
+
+// addedPicture is a picture, other than the front cover, to add to the track.
+type addedPicture struct {
+	picture *flacpicture.MetadataBlockPicture
+	url     string
+}
+
+// AddPicture adds a picture of a type other than the front cover.
+func (t *Track) AddPicture(pictureType flacpicture.PictureType, pic []byte, url string) error {
+	block, err := newPictureBlock(pictureType, pic)
 	if err != nil {
 		return err
 	}
 
-	t.newPicture = img
+	t.addedPictures = append(t.addedPictures, addedPicture{picture: block, url: url})
 
 	return nil
 }
 
-func (t *Track) HasPicture() bool {
-	return t.picture != nil
+// PictureTypes returns the type of each picture, including any changes.
+func (t *Track) PictureTypes() []flacpicture.PictureType {
+	var types []flacpicture.PictureType
+	for _, p := range t.pictures {
+		types = append(types, p.PictureType)
+	}
+	if t.picture == nil && t.newPicture != nil {
+		types = append(types, flacpicture.PictureTypeFrontCover)
+	}
+	for _, p := range t.addedPictures {
+		types = append(types, p.picture.PictureType)
+	}
+	return types
 }
 
-func (t *Track) HasLyrics() bool {
-	if _, ok := t.TagOk(vorbis.LyricsTag); ok {
-		return true
+func newPictureBlock(pictureType flacpicture.PictureType, pic []byte) (*flacpicture.MetadataBlockPicture, error) {
+	mime := http.DetectContentType(pic)
+	if mime != "image/jpeg" && mime != "image/png" {
+		return nil, fmt.Errorf("invalid picture type: %s", mime)
 	}
-	if _, ok := t.TagOk(vorbis.UnsyncedLyricsTag); ok {
-		return true
+	m, err := cover.ReadMetadata(pic)
+	if err != nil {
+		return nil, err
 	}
 
-	return false
+	return &flacpicture.MetadataBlockPicture{
+		PictureType:       pictureType,
+		MIME:              m.MIME,
+		Width:             m.Width,
+		Height:            m.Height,
+		ColorDepth:        m.ColorDepth,
+		IndexedColorCount: m.IndexedColors,
+		ImageData:         pic,
+	}, nil
+}
+
+// CorrectPicture corrects the metadata of the front cover to match the image, without changing the image itself.
+func (t *Track) CorrectPicture() {
+	if t.picture == nil || t.newPicture != nil {
+		return
+	}
+
+	m, err := cover.ReadMetadata(t.picture.ImageData)
+	if err != nil {
+		return
+	}
+
+	if metadataOf(t.picture) == m {
+		return
+	}
+
+	pic := *t.picture
+	pic.MIME = m.MIME
+	pic.Width = m.Width
+	pic.Height = m.Height
+	pic.ColorDepth = m.ColorDepth
+	pic.IndexedColorCount = m.IndexedColors
+	t.newPicture = &pic
+}
+
+// PictureData returns the image data of the front cover, including any changes.
+func (t *Track) PictureData() ([]byte, bool) {
+	if t.newPicture != nil {
+		return t.newPicture.ImageData, true
+	}
+	if t.picture != nil {
+		return t.picture.ImageData, true
+	}
+	return nil, false
+}
+
+func (t *Track) HasPicture() bool {
+	return t.picture != nil || t.newPicture != nil
+}
+
+func (t *Track) HasLyrics() bool {
+	return t.hasTag(vorbis.LyricsTag) || t.hasTag(vorbis.UnsyncedLyricsTag)
+}
+
+// hasTag is whether the track has the tag, and it hasn't been removed.
+func (t *Track) hasTag(tag vorbis.Tag) bool {
+	v, ok := t.TagOk(tag)
+	return ok && len(v) > 0
 }
 
 func (t *Track) HasGenre() bool {
@@ -118,34 +247,96 @@ func (t *Track) HasGenre() bool {
 	return ok && len(v) > 0
 }
 
-func (t *Track) SetUnsyncedLyrics(ctx context.Context, lyrics string, isInternational bool) {
-	if _, ok := t.TagOk(vorbis.LyricsTag); ok {
+// SetUnsyncedLyrics sets the plain lyrics of the track, along with the lyrics provider they came from, as long as
+// they're in one of the languages, if any are given.
+func (t *Track) SetUnsyncedLyrics(ctx context.Context, lyrics string, languages []string, source string) error {
+	if t.hasTag(vorbis.LyricsTag) {
 		panic("check if track already has lyrics")
 	}
-	lyrics = tidyUpLyrics(ctx, lyrics, isInternational)
-	if lyrics == "" {
-		return
+	lyrics, lang, err := tidyUpLyrics(ctx, lyrics, lyrics, languages)
+	if err != nil || lyrics == "" {
+		return err
 	}
 
 	t.newTags[vorbis.UnsyncedLyricsTag] = []string{lyrics}
+	t.newTags[vorbis.LyricsSourceTag] = []string{source}
+	t.setLanguage(lang)
+	return nil
 }
 
-func (t *Track) SetSyncedLyrics(ctx context.Context, lyrics string, isInternational bool) {
-	if _, ok := t.TagOk(vorbis.UnsyncedLyricsTag); ok {
+// SetSyncedLyrics sets the synced lyrics of the track, along with the lyrics provider they came from, as long as
+// they're in one of the languages, if any are given.
+func (t *Track) SetSyncedLyrics(ctx context.Context, lyrics string, languages []string, source string) error {
+	if t.hasTag(vorbis.UnsyncedLyricsTag) {
 		panic("check if track already has lyrics")
 	}
-	lyrics = tidyUpLyrics(ctx, lyrics, isInternational)
-	if lyrics == "" {
-		return
+	lyrics, lang, err := tidyUpLyrics(ctx, lyrics, lrc.Text(lyrics), languages)
+	if err != nil || lyrics == "" {
+		return err
 	}
 
 	t.newTags[vorbis.LyricsTag] = []string{lyrics}
+	t.newTags[vorbis.LyricsSourceTag] = []string{source}
+	t.setLanguage(lang)
+	return nil
+}
+
+// setLanguage sets the language of the lyrics, if it's known and the track doesn't already have one.
+func (t *Track) setLanguage(lang string) {
+	if lang == "" {
+		return
+	}
+	if t.hasTag(vorbis.LanguageTag) {
+		return
+	}
+	t.newTags[vorbis.LanguageTag] = []string{lang}
 }
 
 func (t *Track) SetMusicBrainzAlbumID(id string) {
 	t.newTags[vorbis.MusicBrainzAlbumIDTag] = []string{id}
 }
 
+// SetMusicBrainzDiscID records the disc the track was calculated to be from, optionally writing the disc ID as a tag.
+func (t *Track) SetMusicBrainzDiscID(toc discid.TOC, writeTag bool) {
+	t.discTOC = &toc
+	if writeTag {
+		t.newTags[vorbis.MusicBrainzDiscIDTag] = []string{toc.ID()}
+	}
+}
+
+// DiscTOC returns the table of contents calculated for the disc the track is from.
+func (t *Track) DiscTOC() (discid.TOC, bool) {
+	if t.discTOC == nil {
+		return discid.TOC{}, false
+	}
+	return *t.discTOC, true
+}
+
+// StreamInfo returns the STREAMINFO block for the track, or nil if the file doesn't have one.
+func (t *Track) StreamInfo() *flac.StreamInfoBlock {
+	return t.streamInfo
+}
+
+// Duration returns the length of the track from the total samples in the STREAMINFO block, if known.
+func (t *Track) Duration() (time.Duration, bool) {
+	if t.streamInfo == nil || t.streamInfo.SampleRate == 0 || t.streamInfo.SampleCount == 0 {
+		return 0, false
+	}
+	return time.Duration(t.streamInfo.SampleCount) * time.Second / time.Duration(t.streamInfo.SampleRate), true
+}
+
+// CueSheet returns the embedded CUESHEET block for the track, or nil if the file doesn't have one.
+func (t *Track) CueSheet() *cuesheet.CueSheet {
+	return t.cueSheet
+}
+
+// SetCueSheet sets the cuesheet for a track which doesn't have one embedded, such as from a cue sheet sidecar file.
+func (t *Track) SetCueSheet(c *cuesheet.CueSheet) {
+	if t.cueSheet == nil {
+		t.cueSheet = c
+	}
+}
+
 func (t *Track) SetGenres(genres []string) {
 	t.newTags[vorbis.GenreTag] = genres
 }
@@ -187,10 +378,36 @@ func (t *Track) ValidateTags() error {
 	errs := t.validateExpectedTags()
 	errs = append(errs, t.validateTagValues()...)
 	errs = append(errs, t.validatePicture()...)
+	errs = append(errs, t.validateDuplicatePictures()...)
+	errs = append(errs, t.validateCueSheet()...)
+	errs = append(errs, t.validateReplayGain()...)
 
 	return errors.Join(errs...)
 }
 
+// CopyCueSheetTags populates missing BARCODE & ISRC tags from the embedded cuesheet.
+func (t *Track) CopyCueSheetTags() {
+	if t.cueSheet == nil {
+		return
+	}
+
+	if _, ok := t.TagOk(vorbis.BarcodeTag); !ok && t.cueSheet.HasMediaCatalogNumber() &&
+		t.cueSheet.ValidMediaCatalogNumber() {
+		t.newTags[vorbis.BarcodeTag] = []string{t.cueSheet.MediaCatalogNumber}
+	}
+
+	if _, ok := t.TagOk(vorbis.ISRCTag); ok {
+		return
+	}
+	number, ok := t.IntTag(vorbis.TrackNumberTag)
+	if !ok {
+		return
+	}
+	if cueTrack, ok := t.cueSheet.Track(number); ok && cueTrack.ISRC != "" {
+		t.newTags[vorbis.ISRCTag] = []string{cueTrack.ISRC}
+	}
+}
+
 func (t *Track) validateExpectedTags() []error {
 	var errs []error
 	for _, tag := range []vorbis.Tag{
@@ -252,6 +469,11 @@ func (t *Track) validateTagValues() []error {
 		vorbis.MusicBrainzAlbumArtistIDTag: regexp.MustCompile("^[A-Za-z0-9-]+$"),
 		vorbis.MusicBrainzArtistIDTag:      regexp.MustCompile("^[A-Za-z0-9-]+$"),
 		vorbis.MusicBrainzTrackIDTag:       regexp.MustCompile("^[A-Za-z0-9-]+$"),
+		vorbis.AcoustIDTag:                 regexp.MustCompile("^[A-Za-z0-9-]+$"),
+		vorbis.ReplayGainTrackGainTag:      regexp.MustCompile(`^[+-]?[0-9]+\.[0-9]+ dB$`),
+		vorbis.ReplayGainTrackPeakTag:      regexp.MustCompile(`^[0-9]+\.[0-9]+$`),
+		vorbis.ReplayGainAlbumGainTag:      regexp.MustCompile(`^[+-]?[0-9]+\.[0-9]+ dB$`),
+		vorbis.ReplayGainAlbumPeakTag:      regexp.MustCompile(`^[0-9]+\.[0-9]+$`),
 	} {
 		for _, value := range t.Tag(tag) {
 			if !reg.MatchString(value) {
@@ -267,20 +489,136 @@ func (t *Track) validateTagValues() []error {
 }
 
 func (t *Track) validatePicture() []error {
+	pic := t.picture
+	if t.newPicture != nil {
+		pic = t.newPicture
+	}
+	if pic == nil {
+		return nil
+	}
+
+	mime := http.DetectContentType(pic.ImageData)
+	if mime != "image/jpeg" && mime != "image/png" {
+		return []error{fmt.Errorf("invalid picture type: %s", mime)}
+	}
+
+	actual, err := cover.ReadMetadata(pic.ImageData)
+	if err != nil {
+		return []error{err}
+	}
+	stored := metadataOf(pic)
+
 	var errs []error
+	if stored.MIME != actual.MIME {
+		errs = append(errs, PictureMIMEError{Stored: stored.MIME, Actual: actual.MIME})
+	}
+	for _, field := range []struct {
+		name           string
+		stored, actual uint32
+	}{
+		{"width", stored.Width, actual.Width},
+		{"height", stored.Height, actual.Height},
+		{"colour depth", stored.ColorDepth, actual.ColorDepth},
+		{"indexed colours", stored.IndexedColors, actual.IndexedColors},
+	} {
+		if field.stored != field.actual {
+			errs = append(errs, PictureMetadataError{Field: field.name, Stored: field.stored, Actual: field.actual})
+		}
+	}
 
-	if t.HasPicture() {
-		mime := http.DetectContentType(t.picture.ImageData)
-		if mime != "image/jpeg" && mime != "image/png" {
-			errs = append(errs, fmt.Errorf("invalid picture type: %s", mime))
-		} else if t.picture.MIME != mime {
-			errs = append(errs, fmt.Errorf("incorrect picture type %s - should be %s", mime, t.picture.MIME))
+	return errs
+}
+
+// validateDuplicatePictures checks there's no more than one of each of the picture types which only make sense once.
+func (t *Track) validateDuplicatePictures() []error {
+	counts := map[flacpicture.PictureType]int{}
+	for _, pictureType := range t.PictureTypes() {
+		counts[pictureType]++
+	}
+
+	var errs []error
+	for _, pictureType := range []flacpicture.PictureType{
+		flacpicture.PictureTypeFileIcon,
+		flacpicture.PictureTypeOtherIcon,
+		flacpicture.PictureTypeFrontCover,
+	} {
+		if counts[pictureType] > 1 {
+			errs = append(errs, DuplicatePictureError{Type: pictureType, Count: counts[pictureType]})
 		}
 	}
+	return errs
+}
+
+func metadataOf(pic *flacpicture.MetadataBlockPicture) cover.Metadata {
+	return cover.Metadata{
+		MIME:          pic.MIME,
+		Width:         pic.Width,
+		Height:        pic.Height,
+		ColorDepth:    pic.ColorDepth,
+		IndexedColors: pic.IndexedColorCount,
+	}
+}
+
+func (t *Track) validateCueSheet() []error {
+	if t.cueSheet == nil {
+		return nil
+	}
+
+	var errs []error
+
+	cueTracks := t.cueSheet.AudioTracks()
+	if total := t.Tag(vorbis.TrackTotalTag); len(total) == 1 && total[0] != strconv.Itoa(len(cueTracks)) {
+		errs = append(errs, CueSheetTrackCountError{
+			CueSheetTracks: len(cueTracks),
+			TrackTotal:     total[0],
+		})
+	}
+
+	if leadOut, ok := t.cueSheet.LeadOut(); ok && t.streamInfo != nil && t.streamInfo.SampleCount != 0 &&
+		leadOut.Offset != uint64(t.streamInfo.SampleCount) {
+		errs = append(errs, CueSheetLeadOutError{
+			LeadOut:      leadOut.Offset,
+			TotalSamples: t.streamInfo.SampleCount,
+		})
+	}
+
+	if t.cueSheet.HasMediaCatalogNumber() && !t.cueSheet.ValidMediaCatalogNumber() {
+		errs = append(errs, InvalidMediaCatalogNumberError{Value: t.cueSheet.MediaCatalogNumber})
+	}
+
+	isrcs, ok := t.TagOk(vorbis.ISRCTag)
+	if !ok {
+		return errs
+	}
+	number, ok := t.IntTag(vorbis.TrackNumberTag)
+	if !ok {
+		return errs
+	}
+	cueTrack, ok := t.cueSheet.Track(number)
+	if ok && cueTrack.ISRC != "" && !slices.Equal(isrcs, []string{cueTrack.ISRC}) {
+		errs = append(errs, MismatchedISRCError{
+			TrackNumber: number,
+			CueSheet:    cueTrack.ISRC,
+			Tags:        isrcs,
+		})
+	}
 
 	return errs
 }
 
+// IntTag returns the value of a tag holding a single whole number, such as the track or disc number.
+func (t *Track) IntTag(tag vorbis.Tag) (int, bool) {
+	vees, ok := t.TagOk(tag)
+	if !ok || len(vees) != 1 {
+		return 0, false
+	}
+	i, err := strconv.Atoi(vees[0])
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
 func (t *Track) Tag(key vorbis.Tag) []string {
 	if v, ok := t.newTags[key]; ok {
 		return v
@@ -296,12 +634,37 @@ func (t *Track) TagOk(key vorbis.Tag) ([]string, bool) {
 	return v, ok
 }
 
+// TagNames returns the names of all the tags the track has, including changes, in order.
+func (t *Track) TagNames() []vorbis.Tag {
+	names := map[vorbis.Tag]struct{}{}
+	for name := range t.tags {
+		names[vorbis.Tag(name)] = struct{}{}
+	}
+	for name := range t.newTags {
+		names[name] = struct{}{}
+	}
+
+	var tags []vorbis.Tag
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		if t.hasTag(name) {
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+// Path returns the path of the FLAC file of the track.
+func (t *Track) Path() string {
+	return t.fileName
+}
+
 func (t *Track) String() string {
 	return filepath.Base(t.fileName)
 }
 
 func (t *Track) Save(ctx context.Context, write bool) error {
-	if len(t.newTags) == 0 && t.newPicture == nil {
+	if len(t.newTags) == 0 && t.newPicture == nil && len(t.addedPictures) == 0 && t.newSeekTable == nil &&
+		!t.layoutChanged() {
 		return nil
 	}
 
@@ -327,6 +690,10 @@ func (t *Track) saveChanges(ctx context.Context) error {
 	if err := t.updateFlacWithNewPicture(); err != nil {
 		return err
 	}
+
+	t.updateFlacWithNewSeekTable()
+
+	t.flac.Meta = t.layout()
 	return t.flac.Save(t.fileName)
 }
 
@@ -357,17 +724,36 @@ func (t *Track) updateFlacWithNewTags() error {
 }
 
 func (t *Track) updateFlacWithNewPicture() error {
-	if t.newPicture == nil {
-		return nil
+	if t.newPicture != nil {
+		t.saveBlockToFlac(t.newPicture, t.pictureOffset)
 	}
 
-	t.newPicture.Description = ""
-
-	t.saveBlockToFlac(t.newPicture, t.pictureOffset)
+	for _, p := range t.addedPictures {
+		t.saveBlockToFlac(p.picture, nil)
+	}
 
 	return nil
 }
 
+func (t *Track) updateFlacWithNewSeekTable() {
+	if t.newSeekTable == nil {
+		return
+	}
+
+	i := slices.IndexFunc(t.flac.Meta, func(b *flac.MetaDataBlock) bool { return b.Type == flac.SeekTable })
+	if i != -1 {
+		t.flac.Meta[i] = t.newSeekTable
+		return
+	}
+
+	// A new SEEKTABLE goes straight after the STREAMINFO, so players find it before the rest of the metadata
+	i = 0
+	if len(t.flac.Meta) > 0 && t.flac.Meta[0].Type == flac.StreamInfo {
+		i = 1
+	}
+	t.flac.Meta = slices.Insert(t.flac.Meta, i, t.newSeekTable)
+}
+
 func (t *Track) saveBlockToFlac(block marshalable, offset *int) {
 	m := block.Marshal()
 	if offset != nil {
@@ -375,18 +761,26 @@ func (t *Track) saveBlockToFlac(block marshalable, offset *int) {
 		return
 	}
 
-	t.flac.Meta = append(t.flac.Meta, &m)
+	// New blocks go before any padding at the end, which has to stay last
+	i := len(t.flac.Meta)
+	for i > 0 && t.flac.Meta[i-1].Type == flac.Padding {
+		i--
+	}
+	t.flac.Meta = slices.Insert(t.flac.Meta, i, &m)
 }
 
 func (t *Track) logChanges(ctx context.Context) {
 	logging.FromContext(ctx).WarnContext(ctx, "Updated track", t.changesToSlogAttrs()...)
 }
 
+// changesToSlogAttrs logs the changes to the track, with the tags in order so a track with more than one changed tag,
+// such as a release & disc ID found together, always logs the same way.
 func (t *Track) changesToSlogAttrs() []any {
 	var attrs []any
 	if len(t.newTags) > 0 {
 		var tagAttrs []any
-		for k, v := range t.newTags {
+		for _, k := range slices.Sorted(maps.Keys(t.newTags)) {
+			v := t.newTags[k]
 			value := "__TAG_REMOVED__"
 			if len(v) > 0 {
 				value = strings.Join(v, ",")
@@ -397,60 +791,119 @@ func (t *Track) changesToSlogAttrs() []any {
 	}
 
 	if t.newPicture != nil {
-		attrs = append(attrs, slog.Group("picture",
-			slog.String("url", t.newPicture.Description),
+		var pictureAttrs []any
+		if t.newPictureURL != "" {
+			pictureAttrs = append(pictureAttrs, slog.String("url", t.newPictureURL))
+		}
+		pictureAttrs = append(pictureAttrs,
 			slog.String("mime", t.newPicture.MIME),
 			slog.Uint64("height", uint64(t.newPicture.Height)),
 			slog.Uint64("width", uint64(t.newPicture.Width)),
-		))
+		)
+		attrs = append(attrs, slog.Group("picture", pictureAttrs...))
+	}
+
+	if len(t.addedPictures) > 0 {
+		var pictureAttrs []any
+		for _, p := range t.addedPictures {
+			pictureAttrs = append(pictureAttrs, slog.Group(cover.TypeName(p.picture.PictureType),
+				slog.String("url", p.url),
+				slog.String("mime", p.picture.MIME),
+				slog.Uint64("height", uint64(p.picture.Height)),
+				slog.Uint64("width", uint64(p.picture.Width)),
+			))
+		}
+		attrs = append(attrs, slog.Group("pictures", pictureAttrs...))
+	}
+
+	if t.newSeekTable != nil {
+		attrs = append(attrs, slog.Int("seektable", len(t.newSeekTable.Data)/seekPointSize))
+	}
+
+	if t.layoutChanged() {
+		attrs = append(attrs, slog.String("layout", layoutNames(t.layout())))
 	}
 
 	return attrs
 }
 
-func extractPicture(f *flac.File) (*flacpicture.MetadataBlockPicture, *int, error) {
+// extractPictures returns all the pictures, along with the first front cover & its offset.
+func extractPictures(f *flac.File) (
+	[]*flacpicture.MetadataBlockPicture, *flacpicture.MetadataBlockPicture, *int, error,
+) {
+	var pictures []*flacpicture.MetadataBlockPicture
+	var front *flacpicture.MetadataBlockPicture
+	var frontOffset *int
 	for idx, meta := range f.Meta {
-		if meta.Type == flac.Picture {
-			pic, err := flacpicture.ParseFromMetaDataBlock(*meta)
-			if err != nil {
-				return nil, nil, err
-			}
+		if meta.Type != flac.Picture {
+			continue
+		}
 
-			if pic.PictureType != flacpicture.PictureTypeFrontCover {
-				continue
-			}
+		pic, err := flacpicture.ParseFromMetaDataBlock(*meta)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		pictures = append(pictures, pic)
 
-			return pic, &idx, nil
+		if pic.PictureType == flacpicture.PictureTypeFrontCover && front == nil {
+			front = pic
+			frontOffset = &idx
 		}
 	}
-	return nil, nil, nil
+	return pictures, front, frontOffset, nil
+}
+
+func extractStreamInfo(f *flac.File) (*flac.StreamInfoBlock, error) {
+	if len(f.Meta) == 0 || f.Meta[0].Type != flac.StreamInfo {
+		return nil, nil //nolint:nilnil // STREAMINFO is mandatory but not all files honour that
+	}
+	return f.GetStreamInfo()
+}
+
+func extractCueSheet(f *flac.File) (*cuesheet.CueSheet, error) {
+	for _, meta := range f.Meta {
+		if meta.Type == flac.CueSheet {
+			return cuesheet.ParseFromMetaDataBlock(*meta)
+		}
+	}
+	return nil, nil //nolint:nilnil // most tracks don't have a cuesheet
 }
 
-func tidyUpLyrics(ctx context.Context, text string, isInternational bool) string {
+// tidyUpLyrics replaces marker characters in the lyrics, and detects the language of the text of the lyrics -
+// dropping the lyrics if it's certainly not one of the languages. Lyrics which are only likely to be in another
+// language are kept, without recording the language.
+func tidyUpLyrics(ctx context.Context, lyrics, text string, languages []string) (string, string, error) {
 	// Replace probable marker characters
-	text = strings.NewReplacer(
+	markers := strings.NewReplacer(
 		string('е'), "e",
 		string('ﾠ'), " ",
-	).Replace(text)
+	)
+	lyrics = markers.Replace(lyrics)
 
-	if !strings.ContainsFunc(text, notEnglishOrEmojiCharacters) || isInternational {
-		return text
+	lang, confidence, err := language.Detect(markers.Replace(text))
+	if err != nil {
+		return "", "", err
+	}
+	if confidence == language.Undetected || len(languages) == 0 || slices.Contains(languages, lang) {
+		return lyrics, lang, nil
 	}
 
-	unknown := map[string]struct{}{}
-	for _, e := range text {
-		if notEnglishOrEmojiCharacters(e) {
-			unknown[string(e)] = struct{}{}
-		}
+	if confidence != language.Certain {
+		logging.FromContext(ctx).DebugContext(ctx,
+			"Kept lyrics as it's only likely to be in a language which isn't allowed",
+			slog.String("language", lang),
+			slog.String("allowed", strings.Join(languages, ",")),
+		)
+		return lyrics, "", nil
 	}
 
-	nonEnglishChars := slices.Sorted(maps.Keys(unknown))
 	logging.FromContext(ctx).InfoContext(ctx,
-		"Skipped lyrics as it wasn't english",
-		slog.String("unknown", strings.Join(nonEnglishChars, "")),
-		slog.String("lyrics", text),
+		"Skipped lyrics as it wasn't an allowed language",
+		slog.String("language", lang),
+		slog.String("allowed", strings.Join(languages, ",")),
+		slog.String("lyrics", lyrics),
 	)
-	return ""
+	return "", "", nil
 }
 
 func removeComment(b *flacvorbis.MetaDataBlockVorbisComment, name string) {
@@ -462,26 +915,6 @@ func removeComment(b *flacvorbis.MetaDataBlockVorbisComment, name string) {
 	}
 }
 
-func notEnglishOrEmojiCharacters(r rune) bool {
-	if _, ok := charmap.ISO8859_1.EncodeRune(r); ok {
-		return false
-	}
-
-	// non-marker characters that commonly appear in lyrics that definitely aren't international characters
-	emojis := map[rune]struct{}{
-		'♪': {},
-		'♫': {},
-		'♬': {},
-		'—': {},
-		'–': {},
-		'’': {},
-	}
-	if _, ok := emojis[r]; ok {
-		return false
-	}
-	return true
-}
-
 type marshalable interface {
 	Marshal() flac.MetaDataBlock
 }
@@ -0,0 +1,166 @@
+package track
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/wjam/flac-check/internal/logging"
+	"github.com/wjam/flac-check/internal/music/audio"
+	"github.com/wjam/flac-check/internal/music/vorbis"
+
+	"github.com/go-flac/flacvorbis/v2"
+	"github.com/go-flac/go-flac/v2"
+)
+
+// NewVirtualTrack creates a track for part of a single file album, covering the samples from start (inclusive) to
+// end (exclusive) of the file. The track has the album wide tags of the file along with the given tags.
+func NewVirtualTrack(file *Track, number int, start, end uint64, tags map[vorbis.Tag][]string) *Track {
+	virtualTags := map[string][]string{}
+	for k, v := range file.allTags() {
+		if !slices.Contains(perTrackTags(), k) {
+			virtualTags[string(k)] = v
+		}
+	}
+	for k, v := range tags {
+		virtualTags[string(k)] = v
+	}
+
+	picture := file.picture
+	if file.newPicture != nil {
+		picture = file.newPicture
+	}
+
+	var info *flac.StreamInfoBlock
+	if file.streamInfo != nil {
+		i := *file.streamInfo
+		i.SampleCount = int64(end - start) //nolint:gosec // 36 bit field
+		i.AudioMD5 = nil
+		info = &i
+	}
+
+	return &Track{
+		fileName:   fmt.Sprintf("%s#%02d", file.fileName, number),
+		picture:    picture,
+		tags:       virtualTags,
+		newTags:    map[vorbis.Tag][]string{},
+		streamInfo: info,
+		parent:     file,
+		start:      start,
+		end:        end,
+	}
+}
+
+// SaveAs writes a virtual track out as a FLAC file in its own right, copying the audio frames from the single file
+// album it's part of.
+func (t *Track) SaveAs(ctx context.Context, path string, write bool) error {
+	if t.parent == nil {
+		return errors.New("only a virtual track can be saved as a new file")
+	}
+	if t.parent.streamInfo == nil {
+		return ErrNoStreamInfo
+	}
+
+	if !write {
+		logging.FromContext(ctx).WarnContext(ctx, "Split track", slog.String("file", path))
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	logging.FromContext(ctx).WarnContext(ctx, "Saving split track", slog.String("file", path))
+
+	var frames bytes.Buffer
+	info, err := audio.Cut(&frames, t.parent.audio, t.parent.streamInfo, t.start, t.end)
+	if err != nil {
+		return err
+	}
+
+	comment := flacvorbis.New()
+	if t.parent.comment != nil {
+		comment.Vendor = t.parent.comment.Vendor
+	}
+	tags := t.allTags()
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		for _, v := range tags[k] {
+			if err := comment.Add(string(k), v); err != nil {
+				return err
+			}
+		}
+	}
+
+	streamInfo := audio.MarshalStreamInfo(info)
+	commentBlock := comment.Marshal()
+	meta := []*flac.MetaDataBlock{&streamInfo, &commentBlock}
+	meta = append(meta, t.parent.pictureBlocks()...)
+
+	f := &flac.File{
+		Meta:   meta,
+		Frames: &frames,
+	}
+	return f.Save(path)
+}
+
+// allTags returns the tags of the track, including any changes that haven't been saved yet.
+func (t *Track) allTags() map[vorbis.Tag][]string {
+	tags := map[vorbis.Tag][]string{}
+	for k, v := range t.tags {
+		tags[vorbis.Tag(k)] = v
+	}
+	for k, v := range t.newTags {
+		if len(v) == 0 {
+			delete(tags, k)
+			continue
+		}
+		tags[k] = v
+	}
+	return tags
+}
+
+// pictureBlocks returns all the PICTURE blocks of the file, including a new front cover that hasn't been saved yet.
+func (t *Track) pictureBlocks() []*flac.MetaDataBlock {
+	var blocks []*flac.MetaDataBlock
+	for i, meta := range t.flac.Meta {
+		if meta.Type != flac.Picture {
+			continue
+		}
+		if t.newPicture != nil && t.pictureOffset != nil && *t.pictureOffset == i {
+			continue
+		}
+		blocks = append(blocks, meta)
+	}
+	if t.newPicture != nil {
+		m := t.newPicture.Marshal()
+		blocks = append(blocks, &m)
+	}
+	for _, p := range t.addedPictures {
+		m := p.picture.Marshal()
+		blocks = append(blocks, &m)
+	}
+	return blocks
+}
+
+// perTrackTags are the tags which only describe a single track, so the values from a single file album aren't
+// inherited by the tracks within it.
+func perTrackTags() []vorbis.Tag {
+	return []vorbis.Tag{
+		vorbis.TitleTag,
+		vorbis.TrackNumberTag,
+		vorbis.TrackTotalTag,
+		vorbis.ISRCTag,
+		vorbis.LyricsTag,
+		vorbis.UnsyncedLyricsTag,
+		vorbis.LyricsSourceTag,
+		vorbis.LanguageTag,
+		vorbis.MusicBrainzTrackIDTag,
+		vorbis.AcoustIDTag,
+		vorbis.AcoustIDFingerprintTag,
+		vorbis.CueSheetTag,
+	}
+}
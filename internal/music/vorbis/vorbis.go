@@ -13,6 +13,21 @@ const (
 	TrackTotalTag     Tag = "TRACKTOTAL"
 	LyricsTag         Tag = "LYRICS"
 	UnsyncedLyricsTag Tag = "UNSYNCEDLYRICS"
+	LyricsSourceTag   Tag = "LYRICS_SOURCE"
+	LanguageTag       Tag = "LANGUAGE"
+	BarcodeTag        Tag = "BARCODE"
+	ISRCTag           Tag = "ISRC"
+	CueSheetTag       Tag = "CUESHEET"
+
+	ReplayGainTrackGainTag Tag = "REPLAYGAIN_TRACK_GAIN"
+	ReplayGainTrackPeakTag Tag = "REPLAYGAIN_TRACK_PEAK"
+	ReplayGainAlbumGainTag Tag = "REPLAYGAIN_ALBUM_GAIN"
+	ReplayGainAlbumPeakTag Tag = "REPLAYGAIN_ALBUM_PEAK"
+
+	EstimatedResolutionTag Tag = "ESTIMATED_RESOLUTION"
+
+	AcoustIDTag            Tag = "ACOUSTID_ID"
+	AcoustIDFingerprintTag Tag = "ACOUSTID_FINGERPRINT"
 
 	MusicBrainzAlbumIDTag       Tag = "MUSICBRAINZ_ALBUMID"
 	MusicBrainzDiscIDTag        Tag = "MUSICBRAINZ_DISCID"
@@ -58,15 +58,35 @@ func (c *Client) GetReleaseFromReleaseID(ctx context.Context, albumID string) (R
 	return release, nil
 }
 
-func (c *Client) GetReleaseFromDiscID(ctx context.Context, discID string) (*Release, error) {
+// GetReleaseTracksFromReleaseID gets the release along with the tracks of each medium.
+func (c *Client) GetReleaseTracksFromReleaseID(ctx context.Context, albumID string) (Release, error) {
+	var release Release
+	if err := requests.New(c.configs...).
+		Pathf("./release/%s", albumID).
+		Param("inc", "recordings").
+		Accept("application/json").
+		ToJSON(&release).
+		Fetch(ctx); err != nil {
+		return Release{}, err
+	}
+
+	return release, nil
+}
+
+// GetReleaseFromDiscID looks up the release for a disc ID. If the TOC of the disc is known, it is used to
+// fuzzy-match releases when the disc ID itself hasn't been submitted to MusicBrainz.
+func (c *Client) GetReleaseFromDiscID(ctx context.Context, discID, toc string) (*Release, error) {
 	var discs struct {
 		Releases []Release `json:"releases"`
 	}
-	if err := requests.New(c.configs...).
+	req := requests.New(c.configs...).
 		Pathf("./discid/%s", discID).
 		Accept("application/json").
-		ToJSON(&discs).
-		Fetch(ctx); err != nil {
+		ToJSON(&discs)
+	if toc != "" {
+		req.Param("toc", toc)
+	}
+	if err := req.Fetch(ctx); err != nil {
 		if requests.HasStatusErr(err, http.StatusNotFound) {
 			return nil, ErrNoReleaseFound
 		}
@@ -127,7 +147,10 @@ type Release struct {
 		Back     bool `json:"back"`
 	} `json:"cover-art-archive"`
 	Media []struct {
-		Format string `json:"format"`
+		Format     string  `json:"format"`
+		Position   int     `json:"position"`
+		Tracks     []Track `json:"tracks"`
+		DataTracks []Track `json:"data-tracks"`
 	} `json:"media"`
 	ReleaseGroup struct {
 		ID     string `json:"id"`
@@ -138,6 +161,34 @@ type Release struct {
 	} `json:"release-group"`
 }
 
+// SilentTracks returns the positions, by medium, of the tracks which are silence or data rather than audio.
+func (r Release) SilentTracks() map[int][]int {
+	silent := map[int][]int{}
+	for _, m := range r.Media {
+		for _, t := range m.Tracks {
+			if t.Title == silenceTitle || t.Recording.Title == silenceTitle {
+				silent[m.Position] = append(silent[m.Position], t.Position)
+			}
+		}
+		for _, t := range m.DataTracks {
+			silent[m.Position] = append(silent[m.Position], t.Position)
+		}
+	}
+	return silent
+}
+
+type Track struct {
+	Position  int    `json:"position"`
+	Title     string `json:"title"`
+	Recording struct {
+		Title string `json:"title"`
+	} `json:"recording"`
+}
+
+// silenceTitle is the title of the special purpose recording used for silent tracks.
+// https://musicbrainz.org/doc/Style/Unknown_and_untitled/Special_purpose_track_title
+const silenceTitle = "[silence]"
+
 type ReleaseGroup struct {
 	Relations []struct {
 		URL struct {
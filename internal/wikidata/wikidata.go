@@ -40,9 +40,10 @@ func (c Client) GetLinksForItem(ctx context.Context, id string) (WikiData, error
 }
 
 type WikiData struct {
-	SiteLinks struct {
-		EnWiki struct {
-			Title string `json:"title"`
-		} `json:"enwiki"`
-	} `json:"sitelinks"`
+	// SiteLinks are the pages about the item on each wiki, keyed by the wiki's ID such as "enwiki" or "dewikiquote".
+	SiteLinks map[string]SiteLink `json:"sitelinks"`
+}
+
+type SiteLink struct {
+	Title string `json:"title"`
 }
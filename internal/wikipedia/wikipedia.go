@@ -2,6 +2,8 @@ package wikipedia
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/wjam/flac-check/internal/cache"
@@ -14,24 +16,26 @@ import (
 type Client struct {
 	brainz  *musicbrainz.Client
 	data    *wikidata.Client
+	baseURL string
 	configs []requests.Config
 }
 
-const BaseURL = "https://en.wikipedia.org/w/api.php"
+// BaseURL is the API of each Wikipedia, with {lang} replaced by the language of the Wikipedia.
+const BaseURL = "https://{lang}.wikipedia.org/w/api.php"
 
-func New(brainz *musicbrainz.Client, data *wikidata.Client, opts ...requests.Config) *Client {
+func New(brainz *musicbrainz.Client, data *wikidata.Client, baseURL string, opts ...requests.Config) *Client {
 	return &Client{
-		brainz: brainz,
-		data:   data,
+		brainz:  brainz,
+		data:    data,
+		baseURL: baseURL,
 		configs: append([]requests.Config{
-			func(rb *requests.Builder) {
-				rb.BaseURL(BaseURL)
-			},
 			cache.TransportCache(),
 		}, opts...),
 	}
 }
 
+// GetCoverArtFromMusicBrainzReleaseID returns the URL of the album cover from the English Wikipedia article about
+// the release group, falling back to the lead image of the article in the other languages.
 func (c Client) GetCoverArtFromMusicBrainzReleaseID(ctx context.Context, releaseID string) (string, error) {
 	release, err := c.brainz.GetReleaseFromReleaseID(ctx, releaseID)
 	if err != nil {
@@ -56,12 +60,40 @@ func (c Client) GetCoverArtFromMusicBrainzReleaseID(ctx context.Context, release
 		return "", err
 	}
 
+	articles := wikipediaArticles(data.SiteLinks)
+
+	if title, ok := articles[english]; ok {
+		cover, err := c.albumCover(ctx, title)
+		if err != nil || cover != "" {
+			return cover, err
+		}
+	}
+
+	for _, lang := range slices.Sorted(maps.Keys(articles)) {
+		if lang == english {
+			continue
+		}
+
+		cover, err := c.leadImage(ctx, lang, articles[lang])
+		if err != nil || cover != "" {
+			return cover, err
+		}
+	}
+
+	return "", nil
+}
+
+const english = "en"
+
+// albumCover finds the image of the English article which is in the album covers category.
+func (c Client) albumCover(ctx context.Context, title string) (string, error) {
 	var wikiped wikipedia
 	if err := requests.New(c.configs...).
+		BaseURL(c.url(english)).
 		Param("action", "query").
 		Param("format", "json").
 		Param("prop", "pageimages|categories").
-		Param("titles", data.SiteLinks.EnWiki.Title).
+		Param("titles", title).
 		Param("generator", "images").
 		Param("formatversion", "2").
 		Param("piprop", "original").
@@ -86,6 +118,54 @@ func (c Client) GetCoverArtFromMusicBrainzReleaseID(ctx context.Context, release
 	return "", nil
 }
 
+// leadImage finds the main image of the article, which is the cover of an article about an album. Categories are
+// named differently in each language, so can't be used to find the cover.
+func (c Client) leadImage(ctx context.Context, lang, title string) (string, error) {
+	var wikiped wikipedia
+	if err := requests.New(c.configs...).
+		BaseURL(c.url(lang)).
+		Param("action", "query").
+		Param("format", "json").
+		Param("prop", "pageimages").
+		Param("titles", title).
+		Param("formatversion", "2").
+		Param("piprop", "original").
+		ToJSON(&wikiped).
+		Fetch(ctx); err != nil {
+		return "", err
+	}
+
+	for _, page := range wikiped.Query.Pages {
+		if page.Original.Source != "" {
+			return page.Original.Source, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (c Client) url(lang string) string {
+	return strings.ReplaceAll(c.baseURL, "{lang}", lang)
+}
+
+// wikipediaArticles returns the titles of the Wikipedia articles, keyed by language, from the Wikidata site links.
+func wikipediaArticles(links map[string]wikidata.SiteLink) map[string]string {
+	articles := map[string]string{}
+	for id, link := range links {
+		lang, ok := strings.CutSuffix(id, "wiki")
+		if !ok || slices.Contains(otherWikis(), lang) {
+			continue
+		}
+		articles[strings.ReplaceAll(lang, "_", "-")] = link.Title
+	}
+	return articles
+}
+
+// otherWikis are the site links ending in "wiki" which aren't a Wikipedia.
+func otherWikis() []string {
+	return []string{"commons", "incubator", "mediawiki", "meta", "outreach", "sources", "species", "wikidata"}
+}
+
 type wikipedia struct {
 	Continue struct {
 		Clcontinue string `json:"clcontinue"`
@@ -10,15 +10,22 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/wjam/flac-check/internal/acoustid"
 	"github.com/wjam/flac-check/internal/coverart"
+	"github.com/wjam/flac-check/internal/genius"
+	"github.com/wjam/flac-check/internal/itunes"
 	"github.com/wjam/flac-check/internal/logging"
 	"github.com/wjam/flac-check/internal/lrclib"
 	"github.com/wjam/flac-check/internal/music"
+	"github.com/wjam/flac-check/internal/music/cover"
+	"github.com/wjam/flac-check/internal/music/language"
+	"github.com/wjam/flac-check/internal/music/track"
 	"github.com/wjam/flac-check/internal/musicbrainz"
 	"github.com/wjam/flac-check/internal/wikidata"
 	"github.com/wjam/flac-check/internal/wikipedia"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func main() {
@@ -62,18 +69,161 @@ func root() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().Var(logLevel, "log-level", "Level to log at")
+	cmd.PersistentFlags().Var(logLevel, "log-level", "Level to log at")
 
 	cmd.Flags().BoolVar(&opts.FetchLyrics, "fetch-lyrics", true, "whether to fetch missing lyrics")
+	cmd.Flags().Var(
+		newNamesValue(
+			"lyrics provider", music.LyricsProviderNames(), music.LyricsProviderNames(), &opts.LyricsProviders,
+		),
+		"lyrics-providers",
+		"where to find missing lyrics, in the order to try them, preferring synced lyrics from any provider - "+
+			strings.Join(music.LyricsProviderNames(), ", "),
+	)
+	cmd.Flags().DurationVar(
+		&opts.LyricsDurationTolerance, "lyrics-duration-tolerance", music.DefaultLyricsDurationTolerance,
+		"how far the duration of lyrics found by searching LRCLIB can be from the length of the track",
+	)
+	cmd.Flags().Var(
+		newChoiceValue(
+			[]string{music.SuspiciousLyricsIgnore, music.SuspiciousLyricsReport, music.SuspiciousLyricsRemove},
+			music.SuspiciousLyricsReport, &opts.SuspiciousLyrics,
+		),
+		"suspicious-lyrics",
+		"what to do with lyrics already in a track which are probably for a different version of the track, such as "+
+			"going on after the end of the track, or are just a placeholder - suspicious fetched lyrics are always skipped",
+	)
+	cmd.Flags().BoolVar(
+		&opts.ValidateLyrics, "validate-lyrics", false,
+		"check synced lyrics have valid timestamps, in order and within the length of the track, and that synced & "+
+			"unsynced lyrics are in the right tags, moving & sorting them with --write",
+	)
+	cmd.Flags().StringVar(
+		&opts.GeniusToken, "genius-token", "", "access token of a Genius API client, to find lyrics on Genius",
+	)
 	cmd.Flags().BoolVar(&opts.Write, "write", false, "write changes to disc rather than log them")
+	addLanguageFlags(cmd.Flags(), &opts.LanguageOptions)
+	cmd.Flags().VarP(
+		newStringToIntSliceValue(map[string][]int{}, &opts.SilenceAlbumTracks), "silence-tracks", "",
+		"Tracks which are just silence so may not be present, on top of those MusicBrainz lists as silence or data",
+	)
+	cmd.Flags().BoolVar(
+		&opts.DetectSilence, "detect-silence", false,
+		"decode each track to warn about tracks which are silent",
+	)
+	cmd.Flags().Float64Var(
+		&opts.SilenceThreshold, "silence-threshold", music.DefaultSilenceThreshold,
+		"peak level, in dBFS, at or below which a track is considered silent",
+	)
+	cmd.Flags().BoolVar(
+		&opts.AnalyzeAudio, "analyze-audio", false,
+		"decode the audio of each track to warn about padded bit depths, upsampling & lossy transcodes",
+	)
+	cmd.Flags().BoolVar(
+		&opts.ResolutionTag, "resolution-tag", false,
+		"write the estimated true resolution of each track to the ESTIMATED_RESOLUTION tag when analyzing audio",
+	)
+	cmd.Flags().Float64Var(
+		&opts.MaxClipping, "max-clipping", music.DefaultMaxClipping,
+		"percentage of samples which can be clipped, in runs of consecutive full scale samples, when analyzing audio",
+	)
+	cmd.Flags().Float64Var(
+		&opts.MaxDCOffset, "max-dc-offset", music.DefaultMaxDCOffset,
+		"DC offset, as a percentage of full scale, any channel can have when analyzing audio",
+	)
+	cmd.Flags().StringVar(
+		&opts.Report, "report", "",
+		"file to write a JSON report of the clipping, DC offset & resolution of each album to, which needs --analyze-audio",
+	)
+	cmd.Flags().StringVar(
+		&opts.AcoustIDKey, "acoustid-key", "",
+		"API key of an AcoustID application, to fingerprint tracks missing their AcoustID or MusicBrainz recording "+
+			"and look them up on AcoustID, filling in the MusicBrainz recording & release",
+	)
+	cmd.Flags().BoolVar(
+		&opts.ValidateLayout, "validate-layout", false,
+		"check the metadata blocks of each file are in order - STREAMINFO first, one VORBIS_COMMENT, the SEEKTABLE "+
+			"before any PICTURE and PADDING last - reordering them with --write",
+	)
 	cmd.Flags().StringSliceVar(
-		&opts.InternationalArtists, "international-artists", []string{"BABYMETAL"},
-		"artists which are expected to have lyrics with non-ascii characters",
+		&opts.AllowedApplications, "allowed-applications", track.DefaultApplications(),
+		"IDs of the applications whose APPLICATION blocks are allowed when validating the layout, with others "+
+			"removed by --write",
+	)
+	cmd.Flags().BoolVar(
+		&opts.ValidateSeekTable, "validate-seektable", false,
+		"check the seek points of the SEEKTABLE of each file are in order, within the track and at the start of a "+
+			"frame, generating a new SEEKTABLE with --write if it's missing or invalid",
+	)
+	cmd.Flags().DurationVar(
+		&opts.SeekTableInterval, "seektable-interval", track.DefaultSeekTableInterval,
+		"time between the seek points of a SEEKTABLE generated by --write",
+	)
+	cmd.Flags().DurationVar(
+		&opts.MinTrackDuration, "min-track-duration", music.DefaultMinTrackDuration,
+		"length below which a track is reported as abnormally short",
+	)
+	cmd.Flags().BoolVar(
+		&opts.ComputeDiscID, "compute-disc-id", false,
+		"calculate missing MusicBrainz disc IDs from an embedded cuesheet or track lengths",
+	)
+	cmd.Flags().BoolVar(&opts.WriteDiscID, "write-disc-id", false, "write calculated disc IDs to MUSICBRAINZ_DISCID")
+	cmd.Flags().BoolVar(
+		&opts.CopyCueSheetTags, "copy-cuesheet-tags", false,
+		"populate missing BARCODE & ISRC tags from an embedded cuesheet",
+	)
+	cmd.Flags().BoolVar(
+		&opts.ReplayGain, "replaygain", false,
+		"decode each track to calculate ReplayGain 2.0 track & album gain and peak tags",
+	)
+	cmd.Flags().IntVar(&opts.CoverPolicy.MinSize, "cover-min-size", 0, "minimum width & height, in pixels, of cover art")
+	cmd.Flags().IntVar(&opts.CoverPolicy.MaxSize, "cover-max-size", 0, "maximum width & height, in pixels, of cover art")
+	cmd.Flags().IntVar(&opts.CoverPolicy.MaxBytes, "cover-max-bytes", 0, "maximum size, in bytes, of cover art")
+	cmd.Flags().BoolVar(&opts.CoverPolicy.Square, "cover-square", false, "whether cover art has to be square")
+	cmd.Flags().IntVar(
+		&opts.CoverPolicy.Quality, "cover-quality", cover.DefaultQuality, "JPEG quality of fixed cover art",
+	)
+	cmd.Flags().BoolVar(
+		&opts.FixCover, "fix-cover", false,
+		"replace cover art which doesn't meet the cover policy with the original cover art, downscaled to the maximum "+
+			"size and recompressed as a JPEG",
+	)
+	cmd.Flags().IntVar(&opts.MaxPictures, "max-pictures", 0, "maximum number of pictures per track, 0 for no limit")
+	cmd.Flags().Var(
+		&pictureTypesFlag{value: &opts.AllowedPictureTypes}, "picture-types",
+		"types of picture allowed, such as front-cover or back-cover, with all types allowed if not given",
+	)
+	cmd.Flags().Var(
+		&pictureTypesFlag{value: &opts.FetchPictureTypes}, "fetch-picture-types",
+		"types of picture to fetch from the Cover Art Archive when missing - back-cover, leaflet or media",
+	)
+	cmd.Flags().BoolVar(
+		&opts.SidecarCover, "sidecar-cover", false,
+		"check the front cover matches the cover.jpg or folder.jpg of the album, embedding it in tracks without a "+
+			"picture, and create cover.jpg from the front cover if missing",
+	)
+	cmd.Flags().BoolVar(
+		&opts.CheckReleaseCover, "check-release-cover", false,
+		"check the front cover matches the Cover Art Archive front cover of the MusicBrainz release",
+	)
+	cmd.Flags().Var(
+		newHashAlgorithmValue(cover.DifferenceHashAlgorithm, &opts.CoverHash), "cover-hash",
+		"perceptual hash used to compare cover art",
+	)
+	cmd.Flags().IntVar(
+		&opts.CoverHashDistance, "cover-hash-distance", cover.DefaultMaxDistance,
+		"maximum number of bits the perceptual hashes of cover art can differ by and still be the same image",
+	)
+	cmd.Flags().Var(
+		newNamesValue("cover source", music.CoverSourceNames(), music.DefaultCoverSources(), &opts.CoverSources),
+		"cover-sources",
+		"where to find missing front covers, in the order to try them - "+strings.Join(music.CoverSourceNames(), ", "),
+	)
+	cmd.Flags().StringVar(
+		&opts.CoverDir, "cover-dir", "",
+		"directory of manually sourced covers for the local cover source, named after the MusicBrainz release or "+
+			"release group ID",
 	)
-	cmd.Flags().VarP(newStringToIntSliceValue(map[string][]int{
-		"Bowling for Soup/Drunk Enough to Dance":                         {18, 19, 20, 21, 22, 23, 24, 25, 26, 27},
-		"Various Artists/We're a Happy Family: A Tribute to the Ramones": {17, 18},
-	}, &opts.SilenceAlbumTracks), "silence-tracks", "", "Tracks which are just silence so may not be present")
 	cmd.Flags().Uint16Var(
 		&opts.Parallelism, "parallelism", uint16(math.Max(1, float64(runtime.NumCPU()-1))),
 		"number of albums to process in parallel",
@@ -82,32 +232,146 @@ func root() *cobra.Command {
 	// Flags to aid testing
 
 	const (
+		acoustidBaseURL    = "acoustid-baseurl"
 		coverartBaseURL    = "coverart-baseurl"
+		geniusBaseURL      = "genius-baseurl"
+		itunesBaseURL      = "itunes-baseurl"
 		lrclibBaseURL      = "lrclib-baseurl"
 		musicbrainzBaseURL = "musicbrainz-baseurl"
 		wikipediaBaseURL   = "wikipedia-baseurl"
 		wikidataBaseURL    = "wikidata-baseurl"
 		removeLogAttr      = "remove-log-attr"
 	)
+	cmd.Flags().StringVar(&opts.AcoustidBaseURL, acoustidBaseURL, acoustid.BaseURL, "")
 	cmd.Flags().StringVar(&opts.CoverartBaseURL, coverartBaseURL, coverart.BaseURL, "")
+	cmd.Flags().StringVar(&opts.GeniusBaseURL, geniusBaseURL, genius.BaseURL, "")
+	cmd.Flags().StringVar(&opts.ItunesBaseURL, itunesBaseURL, itunes.BaseURL, "")
 	cmd.Flags().StringVar(&opts.LrclibBaseURL, lrclibBaseURL, lrclib.BaseURL, "")
 	cmd.Flags().StringVar(&opts.MusicbrainzBaseURL, musicbrainzBaseURL, musicbrainz.BaseURL, "")
 	cmd.Flags().StringVar(&opts.WikipediaBaseURL, wikipediaBaseURL, wikipedia.BaseURL, "")
 	cmd.Flags().StringVar(&opts.WikidataBaseURL, wikidataBaseURL, wikidata.BaseURL, "")
-	cmd.Flags().StringSliceVar(&removeLogAttrs, removeLogAttr, []string{}, "")
+	cmd.PersistentFlags().StringSliceVar(&removeLogAttrs, removeLogAttr, []string{}, "")
 
 	for _, s := range []string{
-		coverartBaseURL, lrclibBaseURL, musicbrainzBaseURL, lrclibBaseURL,
-		musicbrainzBaseURL, wikipediaBaseURL, wikidataBaseURL, removeLogAttr,
+		acoustidBaseURL, coverartBaseURL, geniusBaseURL, itunesBaseURL, lrclibBaseURL, musicbrainzBaseURL, lrclibBaseURL,
+		musicbrainzBaseURL, wikipediaBaseURL, wikidataBaseURL,
 	} {
 		if err := cmd.Flags().MarkHidden(s); err != nil {
 			panic(err)
 		}
 	}
+	if err := cmd.PersistentFlags().MarkHidden(removeLogAttr); err != nil {
+		panic(err)
+	}
+
+	cmd.AddCommand(split())
+	cmd.AddCommand(lyrics())
+	cmd.AddCommand(duplicates())
 
 	return cmd
 }
 
+func split() *cobra.Command {
+	var opts music.SplitOptions
+
+	cmd := &cobra.Command{
+		Use:          "split",
+		Short:        "split single file albums with a cue sheet into a FLAC file per track",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			work := music.NewSplit(args[0], opts)
+			return work.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Write, "write", false, "write the split tracks to disc rather than log them")
+	cmd.Flags().BoolVar(
+		&opts.RemoveOriginal, "remove-original", false,
+		"remove the single file album & its cue sheet with --write, once the split tracks have been re-read and "+
+			"checked to decode to exactly its audio",
+	)
+
+	return cmd
+}
+
+func duplicates() *cobra.Command {
+	return &cobra.Command{
+		Use:          "duplicates",
+		Short:        "find tracks with the same audio in different albums, along with how their tags differ",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			work := music.NewDuplicates(args[0])
+			return work.Run(cmd.Context())
+		},
+	}
+}
+
+func lyrics() *cobra.Command {
+	var opts music.LyricsOptions
+
+	cmd := &cobra.Command{
+		Use:   "lyrics",
+		Short: "copy lyrics between tracks and .lrc files next to them",
+	}
+
+	cmd.PersistentFlags().BoolVar(&opts.Write, "write", false, "write changes to disc rather than log them")
+
+	export := &cobra.Command{
+		Use:          "export",
+		Short:        "write the lyrics of each track to a .lrc file with the same name",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			work := music.NewLyricsExport(args[0], opts)
+			return work.Run(cmd.Context())
+		},
+	}
+
+	imp := &cobra.Command{
+		Use:          "import",
+		Short:        "embed the lyrics from the .lrc file with the same name into tracks without lyrics",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			work := music.NewLyricsImport(args[0], opts)
+			return work.Run(cmd.Context())
+		},
+	}
+	addLanguageFlags(imp.Flags(), &opts.LanguageOptions)
+
+	cmd.AddCommand(export, imp)
+
+	return cmd
+}
+
+// addLanguageFlags adds the flags for the languages lyrics are allowed to be in.
+func addLanguageFlags(flags *pflag.FlagSet, opts *music.LanguageOptions) {
+	flags.Var(
+		newNamesValue("language", language.Codes(), []string{language.English}, &opts.Languages), "languages",
+		"languages, as ISO 639-3 codes, lyrics are allowed to be in unless the artist or album has their own - "+
+			strings.Join(language.Codes(), ", "),
+	)
+	flags.Var(
+		newLanguagesMapValue(
+			map[string][]string{"BABYMETAL": {language.Japanese, language.English}}, &opts.ArtistLanguages,
+		),
+		"artist-languages", "languages lyrics by an artist are allowed to be in, as artist=language,language",
+	)
+	flags.Var(
+		newLanguagesMapValue(map[string][]string{}, &opts.AlbumLanguages), "album-languages",
+		"languages lyrics on an album are allowed to be in, as album=language,language",
+	)
+	flags.StringSliceVar(
+		&opts.InternationalArtists, "international-artists", []string{},
+		"artists whose lyrics are allowed to be in any language",
+	)
+	if err := flags.MarkDeprecated("international-artists", "use --artist-languages instead"); err != nil {
+		panic(err)
+	}
+}
+
 func filterAttributesFromLog(ignored []string) func(groups []string, a slog.Attr) slog.Attr {
 	lookup := make(map[string]struct{}, len(ignored))
 	for _, s := range ignored {
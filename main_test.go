@@ -4,27 +4,42 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/md5"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"maps"
+	"math"
+	"math/rand/v2"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/wjam/flac-check/internal/errorutil"
 	"github.com/wjam/flac-check/internal/music"
+	"github.com/wjam/flac-check/internal/music/cover"
+	"github.com/wjam/flac-check/internal/music/cuesheet"
+	"github.com/wjam/flac-check/internal/music/lrc"
 	"github.com/wjam/flac-check/internal/music/track"
 	"github.com/wjam/flac-check/internal/music/vorbis"
 
 	"github.com/go-flac/flacpicture/v2"
 	"github.com/go-flac/flacvorbis/v2"
 	"github.com/go-flac/go-flac/v2"
+	mflac "github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -155,6 +170,8 @@ func TestRoot(t *testing.T) {
 		{name: "funky-lyric-chars-dropped"},
 		{name: "default-log-level"},
 		{name: "musicbrainz-release-id-from-disc-id"},
+		{name: "musicbrainz-release-id-from-computed-disc-id"},
+		{name: "musicbrainz-release-id-from-cuesheet-disc-id"},
 		{name: "picture-from-wikipedia"},
 		{
 			name: "inconsistent-genre-tag",
@@ -271,6 +288,215 @@ func TestRoot(t *testing.T) {
 		},
 		{name: "album-missing-tracks-ignore-silence"},
 		{name: "album-with-silence-tracks-supports-ALBUMARTIST"},
+		{
+			name: "invalid-cuesheet",
+			expectedErrs: []error{
+				track.CueSheetTrackCountError{
+					CueSheetTracks: 2,
+					TrackTotal:     "3",
+				},
+				track.CueSheetLeadOutError{
+					LeadOut:      1000000,
+					TotalSamples: 1176000,
+				},
+				track.InvalidMediaCatalogNumberError{Value: "1234567890123"},
+				track.MismatchedISRCError{
+					TrackNumber: 1,
+					CueSheet:    "GBAYE0000001",
+					Tags:        []string{"GBAYE0000002"},
+				},
+			},
+		},
+		{name: "cuesheet-tags-copied"},
+		{name: "single-file-album-with-cue-sheet"},
+		{name: "single-file-album-skipped-checks"},
+		{
+			name: "single-file-album-missing-title",
+			expectedErrs: []error{
+				errorutil.NotSingleTagValueError{
+					Tag:    vorbis.TitleTag,
+					Values: nil,
+				},
+			},
+		},
+		{name: "split-single-file-album"},
+		{name: "split-single-file-album-remove-original"},
+		{
+			name: "split-single-file-album-unverified",
+			expectedErrs: []error{
+				track.AudioMD5Error{Expected: "00000000000000000000000000000001", Actual: "a9da1da305f8ead94dcec934595fd8fe"},
+			},
+		},
+		{name: "replaygain-tags-written"},
+		{name: "album-missing-silence-tracks-from-musicbrainz"},
+		{name: "silent-track-detected"},
+		{
+			name: "cover-policy-violations",
+			expectedErrs: []error{
+				cover.TooSmallError{Width: 16, Height: 8, MinSize: 10},
+				cover.TooLargeError{Width: 16, Height: 8, MaxSize: 12},
+				cover.TooManyBytesError{Bytes: 91, MaxBytes: 64},
+				cover.NotSquareError{Width: 16, Height: 8},
+			},
+		},
+		{name: "cover-policy-fixed"},
+		{
+			name: "picture-metadata-mismatch",
+			expectedErrs: []error{
+				track.PictureMIMEError{Stored: "image/jpeg", Actual: "image/png"},
+				track.PictureMetadataError{Field: "width", Stored: 2, Actual: 1},
+				track.PictureMetadataError{Field: "colour depth", Stored: 32, Actual: 16},
+			},
+		},
+		{name: "picture-metadata-fixed"},
+		{
+			name: "picture-rules-violations",
+			expectedErrs: []error{
+				track.DuplicatePictureError{Type: flacpicture.PictureTypeFrontCover, Count: 2},
+				music.TooManyPicturesError{Count: 2, Max: 1},
+				music.PictureTypeNotAllowedError{Type: flacpicture.PictureTypeBackCover},
+				music.InconsistentFrontCoverError{Tracks: []string{"track3.flac"}},
+			},
+		},
+		{name: "picture-types-fetched"},
+		{
+			name: "replaygain-inconsistent-album",
+			expectedErrs: []error{
+				music.MissingReplayGainError{Tracks: []string{"track3.flac"}},
+				music.InvalidValueError{
+					Tag:         vorbis.ReplayGainAlbumGainTag,
+					Values:      []string{"-11.00 dB", "-12.00 dB"},
+					Expectation: "single",
+				},
+			},
+		},
+		{name: "sidecar-cover-embedded"},
+		{name: "sidecar-cover-reencoded"},
+		{
+			name: "sidecar-cover-mismatch",
+			expectedErrs: []error{
+				music.SidecarCoverMismatchError{Sidecar: "folder.jpg", Tracks: []string{"track1.flac"}},
+			},
+		},
+		{name: "sidecar-cover-created"},
+		{
+			name: "release-cover-mismatch",
+			expectedErrs: []error{
+				music.CoverMismatchError{ReleaseID: "ID1", Distance: 34},
+			},
+		},
+		{name: "release-cover-matches"},
+		{name: "cover-from-other-language-wikipedia"},
+		{name: "cover-from-itunes"},
+		{name: "cover-from-local-dir"},
+		{name: "cover-from-release-group"},
+		{name: "lyrics-synced-preferred"},
+		{name: "lyrics-from-genius"},
+		{name: "lyrics-from-local-file"},
+		{name: "lyrics-search-duration"},
+		{
+			name: "lyrics-validation-errors",
+			expectedErrs: []error{
+				lrc.InvalidTimestampError{Line: 6, Timestamp: "00:0x.00"},
+				lrc.UntimedLineError{Line: 7, Text: "five"},
+				lrc.TimestampOrderError{Line: 5, Timestamp: "00:03.00", Previous: "00:05.00"},
+				lrc.TimestampBeyondDurationError{Line: 8, Timestamp: "00:12.00", Duration: 10 * time.Second},
+				lrc.LengthHeaderError{Length: "03:00", Duration: 10 * time.Second},
+				track.MisplacedLyricsError{Tag: vorbis.UnsyncedLyricsTag, Synced: true},
+			},
+		},
+		{name: "lyrics-validation-fixed"},
+		{name: "lyrics-export"},
+		{name: "lyrics-import"},
+		{name: "lyrics-allowed-languages"},
+		{name: "lyrics-language-confidence"},
+		{name: "lyrics-language-slang"},
+		{name: "lyrics-suspicious-fetched"},
+		{
+			name: "lyrics-suspicious-report",
+			expectedErrs: []error{
+				track.PlaceholderLyricsError{Lyrics: "Lyrics not available"},
+				track.LyricsLongerThanTrackError{Last: 12 * time.Second, Duration: 10 * time.Second},
+				track.LyricsHeaderMismatchError{
+					Header: "ti",
+					Value:  "other track",
+					Tag:    vorbis.TitleTag,
+					Values: []string{"track2"},
+				},
+				track.PlaceholderLyricsError{Lyrics: "♪ ♪ ♪"},
+			},
+		},
+		{name: "lyrics-suspicious-removed"},
+		{
+			name: "duplicate-audio-found",
+			expectedErrs: []error{
+				music.DuplicateAudioError{
+					MD5:    "89ed89bf1ec8c165463ef5f4cb1f9787",
+					Tracks: []string{"artist3/album2/track1.flac", "artist3/album3/track1.flac"},
+					Differences: []music.TagDifference{
+						{Tag: vorbis.AlbumTag, Values: []string{"album2", "album3"}},
+					},
+				},
+				music.DuplicateAudioError{
+					MD5:    "a55c21d8229f76d534b5b20cdd60868d",
+					Tracks: []string{"artist1/album1/track1.flac", "artist2/album1/track1.flac"},
+					Differences: []music.TagDifference{
+						{Tag: vorbis.ArtistTag, Values: []string{"artist1", "artist2"}},
+					},
+				},
+			},
+		},
+		{name: "fake-hi-res-detected"},
+		{
+			name: "clipping-and-dc-offset",
+			expectedErrs: []error{
+				music.ClippingError{Track: "track2.flac", Clipping: 66.667, Max: music.DefaultMaxClipping},
+				music.DCOffsetError{Track: "track3.flac", Channel: 1, DCOffset: 2, Max: music.DefaultMaxDCOffset},
+				music.DCOffsetError{Track: "track3.flac", Channel: 2, DCOffset: 2, Max: music.DefaultMaxDCOffset},
+			},
+		},
+		{
+			name:         "report-without-analyze-audio",
+			expectedErrs: []error{music.ErrReportWithoutAnalysis},
+		},
+		{
+			name: "album-mixed-audio-properties",
+			expectedErrs: []error{
+				music.MixedAudioPropertyError{
+					Property: "sample rate",
+					Tracks:   map[int][]string{44100: {"track1.flac", "track3.flac", "track4.flac"}, 96000: {"track2.flac"}},
+				},
+				music.MixedAudioPropertyError{
+					Property: "bit depth",
+					Tracks:   map[int][]string{16: {"track1.flac", "track3.flac", "track4.flac"}, 24: {"track2.flac"}},
+				},
+				music.UnknownTotalSamplesError{Track: "track3.flac"},
+				music.ShortTrackError{Track: "track4.flac", Duration: 500 * time.Millisecond, Min: time.Second},
+			},
+		},
+		{name: "acoustid-lookup"},
+		{
+			name: "metadata-layout-invalid",
+			expectedErrs: []error{
+				track.BlockOrderError{Block: "SEEKTABLE", Expectation: "before any PICTURE"},
+				track.BlockOrderError{Block: "PADDING", Expectation: "last"},
+				track.UnexpectedBlockError{Block: `APPLICATION "xyz1"`},
+				track.BlockCountError{Block: "VORBIS_COMMENT", Count: 2, Expectation: "exactly one"},
+			},
+		},
+		{name: "metadata-layout-normalised"},
+		{
+			name: "seektable-invalid",
+			expectedErrs: []error{
+				track.SeekPointError{Index: 1, Sample: 8192, Problem: "offset 1 isn't the start of a frame"},
+				track.SeekPointError{
+					Index: 2, Sample: 4096, Problem: "isn't after the previous point for sample 8192",
+				},
+				track.SeekPointError{Index: 3, Sample: 200000, Problem: "is beyond the 132300 samples of the track"},
+				track.BlockCountError{Block: "SEEKTABLE", Count: 0, Expectation: "exactly one"},
+			},
+		},
+		{name: "seektable-generated"},
 	}
 
 	for _, test := range tests {
@@ -297,7 +523,38 @@ func TestRoot(t *testing.T) {
 }
 
 func assertMusicContent(t *testing.T, dir string, test *txtar.Archive) {
+	var expectedFiles, actualFiles []string
+	for _, file := range test.Files {
+		expectedFiles = append(expectedFiles, filepath.FromSlash(file.Name))
+	}
+	require.NoError(t, filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+		if filepath.Ext(path) == ".flac" || isImageFile(path) || isLyricsFile(path) || isReportFile(path) {
+			rel, err := filepath.Rel(dir, path)
+			actualFiles = append(actualFiles, rel)
+			return err
+		}
+		return err
+	}))
+	assert.ElementsMatch(t, expectedFiles, actualFiles, "FLAC, image, lyrics & report files were different")
+
 	for _, file := range test.Files {
+		if isImageFile(file.Name) {
+			expected, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(file.Data)))
+			require.NoError(t, err)
+			actual, err := os.ReadFile(filepath.Join(dir, file.Name))
+			require.NoError(t, err)
+
+			assert.Equalf(t, expected, actual, "File %s was different", file.Name)
+			continue
+		}
+		if isLyricsFile(file.Name) || isReportFile(file.Name) {
+			actual, err := os.ReadFile(filepath.Join(dir, file.Name))
+			require.NoError(t, err)
+
+			assert.Equalf(t, string(file.Data), string(actual), "File %s was different", file.Name)
+			continue
+		}
+
 		actual := readFlacFile(t, filepath.Join(dir, file.Name))
 		var expected flacFile
 		require.NoError(t, json.Unmarshal(file.Data, &expected))
@@ -306,6 +563,23 @@ func assertMusicContent(t *testing.T, dir string, test *txtar.Archive) {
 	}
 }
 
+// isReportFile returns whether the file is a JSON report, which are compared as plain text in the test archives.
+func isReportFile(name string) bool {
+	return filepath.Ext(name) == ".json"
+}
+
+// isImageFile returns whether the file is an image, which are base64 encoded in the test archives.
+func isImageFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".jpg" || ext == ".png"
+}
+
+// isLyricsFile returns whether the file is a lyrics sidecar, which are plain text in the test archives.
+func isLyricsFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".lrc" || ext == ".txt"
+}
+
 func runMusicTest(t *testing.T, dir string, cmd *cobra.Command, test *txtar.Archive) error {
 	serverBaseURLs := startMockHTTPServers(t, test)
 
@@ -339,6 +613,14 @@ func runMusicTest(t *testing.T, dir string, cmd *cobra.Command, test *txtar.Arch
 			continue
 		}
 
+		if isImageFile(file.Name) {
+			img, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+			require.NoError(t, err)
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file.Name)), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, file.Name), img, 0644))
+			continue
+		}
+
 		require.NoError(t, os.WriteFile(filepath.Join(dir, file.Name), []byte(data), 0644))
 	}
 
@@ -462,14 +744,61 @@ func contextFromTesting(t *testing.T) context.Context {
 }
 
 type flacFile struct {
-	Tags     map[string][]string `json:"tags"`
-	Pictures []flacPicture       `json:"pictures"`
+	// Audio is the signal to generate for the audio frames, rather than a single fake frame
+	Audio      string              `json:"audio,omitempty"`
+	StreamInfo *flacStreamInfo     `json:"streaminfo,omitempty"`
+	Tags       map[string][]string `json:"tags"`
+	Pictures   []flacPicture       `json:"pictures"`
+	CueSheet   *flacCueSheet       `json:"cuesheet,omitempty"`
+	// UnsetMD5 leaves the MD5 of the generated audio out of the STREAMINFO block, as some encoders do
+	UnsetMD5 bool `json:"unsetMD5,omitempty"`
+	// MismatchedMD5 is audio which doesn't decode to the MD5 of the STREAMINFO block, from giving the block an MD5
+	MismatchedMD5 bool `json:"mismatchedMD5,omitempty"`
+	// Layout is the order of the metadata blocks, only given when it isn't the default of STREAMINFO, VORBIS_COMMENT,
+	// each PICTURE then CUESHEET - such as "padding" or "application:riff" blocks, which only exist in the layout
+	Layout []string `json:"layout,omitempty"`
+	// SeekPoints are the seek points of the SEEKTABLE block, if the layout has one
+	SeekPoints []flacSeekPoint `json:"seekpoints,omitempty"`
+}
+
+type flacSeekPoint struct {
+	Sample  uint64 `json:"sample"`
+	Offset  uint64 `json:"offset"`
+	Samples uint16 `json:"samples"`
+}
+
+type flacCueSheet struct {
+	MediaCatalogNumber string              `json:"mcn"`
+	Tracks             []flacCueSheetTrack `json:"tracks"`
+}
+
+type flacCueSheetTrack struct {
+	Offset uint64 `json:"offset"`
+	Number uint8  `json:"number"`
+	ISRC   string `json:"isrc"`
+}
+
+type flacStreamInfo struct {
+	SampleRate int    `json:"sampleRate"`
+	Channels   int    `json:"channels"`
+	BitDepth   int    `json:"bitDepth"`
+	Samples    int64  `json:"samples"`
+	MD5        string `json:"md5,omitempty"`
 }
 
 type flacPicture struct {
 	Type string `json:"type"`
 	Mime string `json:"mime"`
 	Img  string `json:"img"`
+	// Metadata is only given when the metadata stored in the PICTURE block doesn't match the image
+	Metadata *flacPictureMetadata `json:"metadata,omitempty"`
+}
+
+type flacPictureMetadata struct {
+	Width  uint32 `json:"width"`
+	Height uint32 `json:"height"`
+	Depth  uint32 `json:"depth"`
+	Colors uint32 `json:"colors"`
 }
 
 func readFlacFile(t *testing.T, path string) flacFile {
@@ -487,10 +816,102 @@ func readFlacFile(t *testing.T, path string) flacFile {
 
 	pics := extractPictures(t, f)
 
-	return flacFile{
-		Tags:     tags,
-		Pictures: pics,
+	var info *flacStreamInfo
+	var mismatchedMD5 bool
+	if f.Meta[0].Type == flac.StreamInfo {
+		si, err := f.GetStreamInfo()
+		require.NoError(t, err)
+		info = &flacStreamInfo{
+			SampleRate: si.SampleRate,
+			Channels:   si.ChannelCount,
+			BitDepth:   si.BitDepth,
+			Samples:    si.SampleCount,
+		}
+		if !bytes.Equal(si.AudioMD5, make([]byte, md5.Size)) {
+			info.MD5 = hex.EncodeToString(si.AudioMD5)
+			mismatchedMD5 = !verifyAudio(t, path, si)
+		}
+	}
+
+	file := flacFile{
+		StreamInfo:    info,
+		Tags:          tags,
+		Pictures:      pics,
+		CueSheet:      extractCueSheet(t, f),
+		SeekPoints:    extractSeekPoints(t, f),
+		MismatchedMD5: mismatchedMD5,
+	}
+	if layout := readLayout(f); !slices.Equal(layout, defaultLayout(file)) {
+		file.Layout = layout
+	}
+	return file
+}
+
+// readLayout names the metadata blocks in the order they're in.
+func readLayout(f *flac.File) []string {
+	var layout []string
+	for _, meta := range f.Meta {
+		//nolint:exhaustive // only supporting block types required for testing
+		switch meta.Type {
+		case flac.StreamInfo:
+			layout = append(layout, "streaminfo")
+		case flac.VorbisComment:
+			layout = append(layout, "tags")
+		case flac.Picture:
+			layout = append(layout, "picture")
+		case flac.CueSheet:
+			layout = append(layout, "cuesheet")
+		case flac.SeekTable:
+			layout = append(layout, "seektable")
+		case flac.Padding:
+			layout = append(layout, "padding")
+		case flac.Application:
+			layout = append(layout, "application:"+string(meta.Data))
+		default:
+			layout = append(layout, fmt.Sprintf("reserved:%d", meta.Type))
+		}
+	}
+	return layout
+}
+
+// defaultLayout is the order metadata blocks are in when the layout isn't given.
+func defaultLayout(config flacFile) []string {
+	var layout []string
+	if config.StreamInfo != nil {
+		layout = append(layout, "streaminfo")
+	}
+	layout = append(layout, "tags")
+	for range config.Pictures {
+		layout = append(layout, "picture")
+	}
+	if config.CueSheet != nil {
+		layout = append(layout, "cuesheet")
+	}
+	return layout
+}
+
+// verifyAudio decodes the audio frames to check they match the STREAMINFO block, returning whether they match its MD5.
+func verifyAudio(t *testing.T, path string, info *flac.StreamInfoBlock) bool {
+	stream, err := mflac.Open(path)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.Close())
+	}()
+
+	sum := md5.New()
+	var samples int64
+	for {
+		f, err := stream.ParseNext()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		f.Hash(sum)
+		samples += int64(f.BlockSize)
 	}
+
+	assert.Equal(t, info.SampleCount, samples, "decoded samples")
+	return bytes.Equal(info.AudioMD5, sum.Sum(nil))
 }
 
 func extractPictures(t *testing.T, f *flac.File) []flacPicture {
@@ -509,51 +930,294 @@ func extractPictures(t *testing.T, f *flac.File) []flacPicture {
 			picType, ok := pictureTypeToString[pic.PictureType]
 			require.Truef(t, ok, "Unexpected type %v", pic.PictureType)
 
+			actual, err := cover.ReadMetadata(pic.ImageData)
+			require.NoError(t, err)
+			stored := flacPictureMetadata{
+				Width:  pic.Width,
+				Height: pic.Height,
+				Depth:  pic.ColorDepth,
+				Colors: pic.IndexedColorCount,
+			}
+
+			var metadata *flacPictureMetadata
+			if stored != (flacPictureMetadata{actual.Width, actual.Height, actual.ColorDepth, actual.IndexedColors}) {
+				metadata = &stored
+			}
+
 			pics = append(pics, flacPicture{
-				Type: picType,
-				Mime: pic.MIME,
-				Img:  base64.StdEncoding.EncodeToString(pic.ImageData),
+				Type:     picType,
+				Mime:     pic.MIME,
+				Img:      base64.StdEncoding.EncodeToString(pic.ImageData),
+				Metadata: metadata,
 			})
 		}
 	}
 	return pics
 }
 
+func extractCueSheet(t *testing.T, f *flac.File) *flacCueSheet {
+	for _, meta := range f.Meta {
+		if meta.Type != flac.CueSheet {
+			continue
+		}
+		c, err := cuesheet.ParseFromMetaDataBlock(*meta)
+		require.NoError(t, err)
+
+		cue := &flacCueSheet{MediaCatalogNumber: c.MediaCatalogNumber}
+		for _, track := range c.Tracks {
+			cue.Tracks = append(cue.Tracks, flacCueSheetTrack{
+				Offset: track.Offset,
+				Number: track.Number,
+				ISRC:   track.ISRC,
+			})
+		}
+		return cue
+	}
+	return nil
+}
+
+func extractSeekPoints(t *testing.T, f *flac.File) []flacSeekPoint {
+	var points []flacSeekPoint
+	for _, meta := range f.Meta {
+		if meta.Type != flac.SeekTable {
+			continue
+		}
+		seekTable, err := track.ParseSeekTable(meta.Data)
+		require.NoError(t, err)
+
+		for _, p := range seekTable {
+			points = append(points, flacSeekPoint(p))
+		}
+	}
+	return points
+}
+
 func makeFlacFile(t *testing.T, file string, content []byte) {
 	var config flacFile
 	require.NoError(t, json.Unmarshal(content, &config))
 
-	blocks := []*flac.MetaDataBlock{
-		buildFlacTags(t, config.Tags),
-	}
-	for _, p := range config.Pictures {
-		stringToPictureType := map[string]flacpicture.PictureType{
-			"cover": flacpicture.PictureTypeFrontCover,
-			"back":  flacpicture.PictureTypeBackCover,
-		}
-		picType, ok := stringToPictureType[p.Type]
-		if !ok {
-			t.Fatalf("unknown picture type: %s", p.Type)
+	frames := []byte{0xFF, 0xF8}
+	if config.Audio != "" {
+		frames = encodeAudio(t, config.Audio, config.StreamInfo)
+		if config.UnsetMD5 {
+			config.StreamInfo.MD5 = ""
 		}
+	}
 
-		blocks = append(blocks, buildFlacPicture(t, picType, p.Img, p.Mime))
+	layout := config.Layout
+	if layout == nil {
+		layout = defaultLayout(config)
+	}
+
+	var blocks []*flac.MetaDataBlock
+	pictures := config.Pictures
+	for _, name := range layout {
+		kind, arg, _ := strings.Cut(name, ":")
+		switch kind {
+		case "streaminfo":
+			blocks = append(blocks, buildFlacStreamInfo(t, config.StreamInfo))
+		case "tags":
+			blocks = append(blocks, buildFlacTags(t, config.Tags))
+		case "picture":
+			require.NotEmpty(t, pictures, "more pictures in the layout than given")
+			blocks = append(blocks, buildFlacPicture(t, pictures[0]))
+			pictures = pictures[1:]
+		case "cuesheet":
+			blocks = append(blocks, buildFlacCueSheet(config.CueSheet))
+		case "seektable":
+			blocks = append(blocks, buildFlacSeekTable(config.SeekPoints))
+		case "padding":
+			blocks = append(blocks, &flac.MetaDataBlock{Type: flac.Padding, Data: make([]byte, 16)})
+		case "application":
+			blocks = append(blocks, &flac.MetaDataBlock{Type: flac.Application, Data: []byte(arg)})
+		default:
+			t.Fatalf("unknown metadata block: %s", name)
+		}
 	}
 
-	saveFlacFile(t, file, blocks...)
+	saveFlacFile(t, file, frames, blocks...)
 }
 
-func saveFlacFile(t *testing.T, path string, blocks ...*flac.MetaDataBlock) {
+func buildFlacSeekTable(points []flacSeekPoint) *flac.MetaDataBlock {
+	var data []byte
+	for _, p := range points {
+		data = binary.BigEndian.AppendUint64(data, p.Sample)
+		data = binary.BigEndian.AppendUint64(data, p.Offset)
+		data = binary.BigEndian.AppendUint16(data, p.Samples)
+	}
+	return &flac.MetaDataBlock{Type: flac.SeekTable, Data: data}
+}
+
+func saveFlacFile(t *testing.T, path string, frames []byte, blocks ...*flac.MetaDataBlock) {
 	dir := filepath.Dir(path)
 	require.NoError(t, os.MkdirAll(dir, 0755))
 
 	f := flac.File{
 		Meta:   blocks,
-		Frames: bytes.NewBuffer([]byte{0xFF, 0xF8}),
+		Frames: bytes.NewBuffer(frames),
 	}
 
 	require.NoError(t, f.Save(path))
 }
 
+// audioBlockSize is the block size of generated audio frames.
+const audioBlockSize = 4096
+
+// encodeAudio encodes the given signal as audio frames with a fixed block size, populating the MD5 of the STREAMINFO
+// unless one is given to fake audio which doesn't match it.
+func encodeAudio(t *testing.T, signal string, info *flacStreamInfo) []byte {
+	require.NotNil(t, info, "audio requires a STREAMINFO block")
+	generate, ok := signals(info.SampleRate, float64(int(1)<<(info.BitDepth-1)))[signal]
+	require.True(t, ok, "unknown signal")
+
+	var buf bytes.Buffer
+	enc, err := mflac.NewEncoder(&buf, &meta.StreamInfo{
+		BlockSizeMin:  audioBlockSize,
+		BlockSizeMax:  audioBlockSize,
+		SampleRate:    uint32(info.SampleRate),
+		NChannels:     uint8(info.Channels),
+		BitsPerSample: uint8(info.BitDepth),
+		NSamples:      uint64(info.Samples),
+	})
+	require.NoError(t, err)
+	headerLength := buf.Len()
+
+	channels := map[int]frame.Channels{1: frame.ChannelsMono, 2: frame.ChannelsLR}[info.Channels]
+	sum := md5.New()
+	for offset := 0; offset < int(info.Samples); offset += audioBlockSize {
+		n := min(audioBlockSize, int(info.Samples)-offset)
+		f := &frame.Frame{
+			Header: frame.Header{
+				HasFixedBlockSize: true,
+				BlockSize:         uint16(n),
+				SampleRate:        uint32(info.SampleRate),
+				Channels:          channels,
+				BitsPerSample:     uint8(info.BitDepth),
+			},
+		}
+		for c := range info.Channels {
+			samples := make([]int32, n)
+			for i := range samples {
+				samples[i] = int32(generate(offset+i, c))
+			}
+			f.Subframes = append(f.Subframes, &frame.Subframe{
+				SubHeader: frame.SubHeader{Pred: frame.PredVerbatim},
+				Samples:   samples,
+				NSamples:  n,
+			})
+		}
+		f.Hash(sum)
+		require.NoError(t, enc.WriteFrame(f))
+	}
+	require.NoError(t, enc.Close())
+
+	if info.MD5 == "" {
+		info.MD5 = hex.EncodeToString(sum.Sum(nil))
+	}
+
+	return buf.Bytes()[headerLength:]
+}
+
+// signals are the signals which can be generated for audio, giving each sample of each channel. Sine waves are 440Hz,
+// at an amplitude relative to full scale.
+func signals(sampleRate int, fullScale float64) map[string]func(i, c int) float64 {
+	sine := func(scale float64) func(i, c int) float64 {
+		amplitude := scale * fullScale
+		return func(i, c int) float64 {
+			return amplitude * math.Sin(2*math.Pi*440*float64(i)/float64(sampleRate)+float64(c))
+		}
+	}
+	half := sine(0.5)
+	noise := rand.New(rand.NewPCG(1, 2))
+
+	return map[string]func(i, c int) float64{
+		"sine":       half,
+		"quiet-sine": sine(0.125),
+		"silence":    sine(0),
+		// A sine wave at twice full scale, clipped for two thirds of each cycle
+		"clipped-sine": func(i, c int) float64 {
+			return max(-fullScale, min(fullScale-1, 4*half(i, c)))
+		},
+		// A sine wave with a DC offset of 2% of full scale
+		"offset-sine": func(i, c int) float64 {
+			return half(i, c) + 0.02*fullScale
+		},
+		// White noise has every frequency up to the Nyquist frequency
+		"noise": func(int, int) float64 {
+			return fullScale * (noise.Float64() - 0.5)
+		},
+		// White noise with only 16 bits of precision, as if padded from 16 bit audio
+		"padded-noise": func(int, int) float64 {
+			return fullScale * math.Round((noise.Float64()-0.5)*(1<<15)) / (1 << 15)
+		},
+		// Sine waves every 200Hz up to 21kHz, as if upsampled from 44.1kHz
+		"band-limited": func(i, c int) float64 {
+			var sum float64
+			for f := 200; f <= 21000; f += 200 {
+				sum += math.Sin(2*math.Pi*float64(f)*float64(i)/float64(sampleRate) + float64(f*(c+1)))
+			}
+			return fullScale * sum / 210
+		},
+	}
+}
+
+func buildFlacStreamInfo(t *testing.T, info *flacStreamInfo) *flac.MetaDataBlock {
+	data := make([]byte, 34)
+	// Frame sizes are left as zero, meaning unknown, as are block sizes without generated audio
+	if info.MD5 != "" {
+		binary.BigEndian.PutUint16(data[0:2], audioBlockSize)
+		binary.BigEndian.PutUint16(data[2:4], audioBlockSize)
+	}
+	packed := uint64(info.SampleRate)<<44 |
+		uint64(info.Channels-1)<<41 |
+		uint64(info.BitDepth-1)<<36 |
+		uint64(info.Samples)
+	binary.BigEndian.PutUint64(data[10:18], packed)
+	if info.MD5 != "" {
+		sum, err := hex.DecodeString(info.MD5)
+		require.NoError(t, err)
+		copy(data[18:], sum)
+	}
+
+	return &flac.MetaDataBlock{
+		Type: flac.StreamInfo,
+		Data: data,
+	}
+}
+
+// buildFlacCueSheet builds a CD-DA cuesheet, where every track other than the lead-out starts at index point 1.
+func buildFlacCueSheet(cue *flacCueSheet) *flac.MetaDataBlock {
+	var data bytes.Buffer
+	mcn := make([]byte, 128)
+	copy(mcn, cue.MediaCatalogNumber)
+	data.Write(mcn)
+	data.Write(binary.BigEndian.AppendUint64(nil, 88200))
+	data.WriteByte(0x80)
+	data.Write(make([]byte, 258))
+	data.WriteByte(byte(len(cue.Tracks)))
+	for _, track := range cue.Tracks {
+		data.Write(binary.BigEndian.AppendUint64(nil, track.Offset))
+		data.WriteByte(track.Number)
+		isrc := make([]byte, 12)
+		copy(isrc, track.ISRC)
+		data.Write(isrc)
+		data.Write(make([]byte, 14))
+		if track.Number == cuesheet.LeadOutTrackNumber {
+			data.WriteByte(0)
+			continue
+		}
+		data.WriteByte(1)
+		data.Write(make([]byte, 8))
+		data.WriteByte(1)
+		data.Write(make([]byte, 3))
+	}
+
+	return &flac.MetaDataBlock{
+		Type: flac.CueSheet,
+		Data: data.Bytes(),
+	}
+}
+
 func buildFlacTags(t *testing.T, tags map[string][]string) *flac.MetaDataBlock {
 	comment := flacvorbis.New()
 
@@ -567,12 +1231,37 @@ func buildFlacTags(t *testing.T, tags map[string][]string) *flac.MetaDataBlock {
 	return &block
 }
 
-func buildFlacPicture(t *testing.T, picType flacpicture.PictureType, img, mime string) *flac.MetaDataBlock {
-	content, err := base64.StdEncoding.DecodeString(img)
+// buildFlacPicture builds a PICTURE block with the metadata of the image, unless the metadata is given - which allows
+// for pictures which don't match their metadata.
+func buildFlacPicture(t *testing.T, p flacPicture) *flac.MetaDataBlock {
+	stringToPictureType := map[string]flacpicture.PictureType{
+		"cover": flacpicture.PictureTypeFrontCover,
+		"back":  flacpicture.PictureTypeBackCover,
+	}
+	picType, ok := stringToPictureType[p.Type]
+	if !ok {
+		t.Fatalf("unknown picture type: %s", p.Type)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(p.Img)
 	require.NoError(t, err)
 
-	picture, err := flacpicture.NewFromImageData(picType, "", content, mime)
+	m, err := cover.ReadMetadata(content)
 	require.NoError(t, err)
+	metadata := flacPictureMetadata{m.Width, m.Height, m.ColorDepth, m.IndexedColors}
+	if p.Metadata != nil {
+		metadata = *p.Metadata
+	}
+
+	picture := flacpicture.MetadataBlockPicture{
+		PictureType:       picType,
+		MIME:              p.Mime,
+		Width:             metadata.Width,
+		Height:            metadata.Height,
+		ColorDepth:        metadata.Depth,
+		IndexedColorCount: metadata.Colors,
+		ImageData:         content,
+	}
 
 	block := picture.Marshal()
 